@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/jupiterozeye/context/internal/deps"
+	"github.com/spf13/cobra"
+)
+
+var (
+	depsFormat string
+	depsNoCopy bool
+	depsGraph  bool
+	depsExtern bool
+)
+
+var depsCmd = &cobra.Command{
+	Use:   "deps [path]",
+	Short: "Summarize dependency manifests and copy to clipboard",
+	Long: `Find the dependency manifests (go.mod, package.json, pyproject.toml,
+requirements.txt, Cargo.toml) in the specified directory, or the nearest parent
+that has any, and summarize toolchain versions, direct dependencies, overrides
+and workspace layout.
+
+With --graph, show the import graph between the packages of the Go module
+instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runDeps,
+}
+
+func init() {
+	rootCmd.AddCommand(depsCmd)
+	depsCmd.Flags().StringVarP(&depsFormat, "format", "f", "tree", "Output format: tree|json|markdown, or with --graph also dot|mermaid")
+	depsCmd.Flags().BoolVar(&depsGraph, "graph", false, "Show the import graph of the Go module's packages")
+	depsCmd.Flags().BoolVar(&depsExtern, "external", false, "With --graph, include imports of other modules")
+	depsCmd.Flags().BoolVarP(&depsNoCopy, "no-copy", "c", false, "Print only, don't copy to clipboard")
+}
+
+func runDeps(cmd *cobra.Command, args []string) error {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	generator := deps.NewGenerator(deps.Options{
+		Format:   depsFormat,
+		External: depsExtern,
+	})
+
+	if depsGraph {
+		output, err := generator.GenerateGraph(path)
+		if err != nil {
+			return fmt.Errorf("failed to build import graph: %w", err)
+		}
+		return emit(output, depsNoCopy)
+	}
+
+	output, err := generator.Generate(path)
+	if err != nil {
+		return fmt.Errorf("failed to summarize dependencies: %w", err)
+	}
+
+	// Manifests can pin dependencies to URLs with tokens in them.
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+	output = redactor.Redact(output)
+	reportRedactions(redactor)
+
+	return emit(output, depsNoCopy)
+}
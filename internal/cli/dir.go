@@ -1,10 +1,21 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
-	"github.com/jupiterozeye/context/internal/clipboard"
+	"github.com/jupiterozeye/context/internal/atomicfile"
+	"github.com/jupiterozeye/context/internal/color"
 	"github.com/jupiterozeye/context/internal/dir"
+	"github.com/jupiterozeye/context/internal/picker"
+	"github.com/jupiterozeye/context/internal/watch"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +25,23 @@ var (
 	dirHidden   bool
 	dirFormat   string
 	dirNoCopy   bool
+	dirKeyFiles bool
+	dirSecrets  bool
+	dirPick     bool
+	dirContents bool
+	dirWatch    bool
+	dirOutput   string
+	dirStats    bool
+	dirHash     string
+	dirCharset  string
+	dirFull     bool
+	dirCompact  bool
+	dirFocus    []string
+	dirGen      string
+	dirTop      int
+	dirCheck    string
+	dirTimeout  time.Duration
+	dirMaxFiles int
 )
 
 var dirCmd = &cobra.Command{
@@ -29,8 +57,25 @@ func init() {
 	dirCmd.Flags().IntVarP(&dirDepth, "depth", "d", 0, "Max depth (0 = unlimited)")
 	dirCmd.Flags().StringVarP(&dirExclude, "exclude", "e", "", "Comma-separated patterns to exclude (e.g., 'node_modules,.git')")
 	dirCmd.Flags().BoolVarP(&dirHidden, "hidden", "H", false, "Include hidden files")
-	dirCmd.Flags().StringVarP(&dirFormat, "format", "f", "tree", "Output format: tree|json|markdown")
+	dirCmd.Flags().StringVarP(&dirFormat, "format", "f", "tree", "Output format: tree|json|markdown, or top for the size report alone")
+	dirCmd.Flags().StringVar(&dirCharset, "charset", "unicode", "Tree connectors: unicode|ascii|indent|rounded")
+	dirCmd.Flags().BoolVar(&dirFull, "full-paths", false, "Show each entry's path relative to the root instead of its name")
+	dirCmd.Flags().BoolVar(&dirCompact, "compact-dirs", false, "Show chains of single-child directories as one entry (src/main/java/)")
+	dirCmd.Flags().StringArrayVar(&dirFocus, "focus", nil, "Show PATH and its surroundings in full, collapsing unrelated directories (repeatable)")
+	dirCmd.Flags().StringVar(&dirGen, "generated", "show", "Generated, vendored and lock files: show|mark|skip")
+	dirCmd.Flags().IntVar(&dirTop, "top", 0, "Report the N largest files and directories by size and lines (default 10 with -f top)")
+	dirCmd.Flags().StringVar(&dirCheck, "check", "", "Compare against a layout spec (JSON or tree text) and fail on differences")
+	dirCmd.Flags().DurationVar(&dirTimeout, "timeout", 0, "Stop walking after this long and output a partial tree (e.g. 10s, 0 = no limit)")
+	dirCmd.Flags().IntVar(&dirMaxFiles, "max-files", 0, "Stop walking after this many files and directories (0 = unlimited)")
 	dirCmd.Flags().BoolVarP(&dirNoCopy, "no-copy", "c", false, "Print only, don't copy to clipboard")
+	dirCmd.Flags().BoolVar(&dirKeyFiles, "key-files", false, "Append contents of manifest and build files (go.mod, Makefile, package.json, ...)")
+	dirCmd.Flags().BoolVar(&dirStats, "stats", false, "Count files, code, comment and blank lines per language and directory")
+	dirCmd.Flags().StringVar(&dirHash, "hash", "", "Include per-file and directory digests in JSON output: sha256|xxhash")
+	dirCmd.Flags().BoolVarP(&dirPick, "interactive", "i", false, "Choose files to include in a full-screen tree browser")
+	dirCmd.Flags().BoolVar(&dirContents, "contents", false, "With --interactive, include the selected files' contents")
+	dirCmd.Flags().StringVarP(&dirOutput, "output", "o", "", "Write to FILE (atomically) instead of printing and copying")
+	dirCmd.Flags().BoolVarP(&dirWatch, "watch", "w", false, "With --output, re-render whenever the directory changes")
+	dirCmd.Flags().BoolVar(&dirSecrets, "allow-secrets", false, "Include contents of files that look like secrets (.env, keys, credentials)")
 }
 
 func runDir(cmd *cobra.Command, args []string) error {
@@ -39,26 +84,232 @@ func runDir(cmd *cobra.Command, args []string) error {
 		path = args[0]
 	}
 
-	generator := dir.NewGenerator(dir.Options{
-		MaxDepth:     dirDepth,
-		Exclude:      dirExclude,
-		IncludeHidden: dirHidden,
-		Format:       dirFormat,
-	})
+	if dirWatch {
+		if dirOutput == "" {
+			return fmt.Errorf("--watch requires --output FILE")
+		}
+		if dirPick {
+			return fmt.Errorf("--watch cannot be combined with --interactive")
+		}
+		return watchDir(path)
+	}
+
+	useColor, err := colorEnabled()
+	if err != nil {
+		return err
+	}
+	useColor = useColor && dirOutput == ""
+
+	opts := dirOptions()
+	opts.GitStatus = useColor
+	generator := dir.NewGenerator(opts)
+
+	tree, err := walkDir(generator, path)
+	if err != nil {
+		return fmt.Errorf("failed to generate tree: %w", err)
+	}
+	warnIncomplete(tree)
+
+	if dirPick {
+		result, err := picker.Run(tree, dirContents)
+		if errors.Is(err, picker.ErrCancelled) {
+			fmt.Fprintln(os.Stderr, "Cancelled.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		tree.Entries = dir.Select(tree.Entries, result.Selected)
+		if result.IncludeContents {
+			tree.Files = generator.ReadFiles(tree.Root, tree.FilePaths())
+		}
+	}
+
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+	redactTree(redactor, tree)
+
+	warnSecrets(tree.Secrets, dirSecrets)
+	reportRedactions(redactor)
 
-	output, err := generator.Generate(path)
+	output, err := generator.Format(tree)
 	if err != nil {
 		return fmt.Errorf("failed to generate tree: %w", err)
 	}
 
-	fmt.Print(output)
+	if dirOutput != "" {
+		if err := atomicfile.Write(dirOutput, []byte(output), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dirOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", dirOutput)
+		return checkFailed(cmd, tree)
+	}
 
-	if !dirNoCopy {
-		if err := clipboard.Copy(output); err != nil {
-			return fmt.Errorf("failed to copy to clipboard: %w", err)
+	display := output
+	if useColor && dirFormat != "json" && dirFormat != "top" {
+		opts.Colors = color.NewPalette()
+		if display, err = dir.NewGenerator(opts).Format(tree); err != nil {
+			return fmt.Errorf("failed to generate tree: %w", err)
 		}
-		fmt.Println("\nCopied to clipboard!")
 	}
 
-	return nil
-}
\ No newline at end of file
+	if err := emitStyled(display, output, dirNoCopy); err != nil {
+		return err
+	}
+	return checkFailed(cmd, tree)
+}
+
+// checkFailed returns an error, for a non-zero exit status, when --check
+// found differences. The report itself is already part of the output.
+func checkFailed(cmd *cobra.Command, tree *dir.Tree) error {
+	if tree.Check == nil || tree.Check.Problems() == 0 {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return fmt.Errorf("layout check failed: %d problem(s): %w", tree.Check.Problems(), ErrReported)
+}
+
+// dirOptions returns the generator options selected by the dir flags.
+func dirOptions() dir.Options {
+	top := dirTop
+	if dirFormat == "top" && top <= 0 {
+		top = 10
+	}
+	return dir.Options{
+		MaxDepth:      dirDepth,
+		Exclude:       dirExclude,
+		IncludeHidden: dirHidden,
+		Format:        dirFormat,
+		KeyFiles:      dirKeyFiles,
+		AllowSecrets:  dirSecrets,
+		Stats:         dirStats,
+		Hash:          dirHash,
+		Charset:       dirCharset,
+		FullPaths:     dirFull,
+		CompactDirs:   dirCompact,
+		Focus:         dirFocus,
+		Generated:     dirGen,
+		Top:           top,
+		Layout:        dirCheck,
+		MaxFiles:      dirMaxFiles,
+	}
+}
+
+// walkDir walks path within --timeout. Interrupting the walk with Ctrl+C
+// stops it early too, leaving a partial tree rather than no output; a second
+// Ctrl+C kills the process as usual.
+func walkDir(generator *dir.Generator, path string) (*dir.Tree, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if dirTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dirTimeout)
+		defer cancel()
+	}
+	return generator.WalkContext(ctx, path)
+}
+
+// warnIncomplete prints a warning to stderr when the walk stopped early, so a
+// partial tree isn't mistaken for the whole directory.
+func warnIncomplete(tree *dir.Tree) {
+	if tree.Partial != "" {
+		fmt.Fprintf(os.Stderr, "Warning: reading below the display depth %s, sizes, stats and digests are partial\n", tree.Partial)
+	}
+	if tree.Check != nil && tree.Check.Incomplete != "" {
+		fmt.Fprintf(os.Stderr, "Warning: reading for the layout check %s, required paths were not checked\n", tree.Check.Incomplete)
+	}
+	if tree.Incomplete == "" {
+		return
+	}
+	reason := tree.Incomplete
+	if reason == "timed out" {
+		reason += " after " + dirTimeout.String()
+	}
+	fmt.Fprintf(os.Stderr, "Warning: walk %s, the tree is incomplete\n", reason)
+}
+
+// watchDir renders the tree to --output and re-renders it after every change
+// under path until interrupted.
+func watchDir(path string) error {
+	output, err := filepath.Abs(dirOutput)
+	if err != nil {
+		return err
+	}
+	generator := dir.NewGenerator(dirOptions())
+	render := func() error {
+		tree, err := walkDir(generator, path)
+		if err != nil {
+			return fmt.Errorf("failed to generate tree: %w", err)
+		}
+		warnIncomplete(tree)
+		redactor, err := newRedactor()
+		if err != nil {
+			return err
+		}
+		redactTree(redactor, tree)
+		reportRedactions(redactor)
+		formatted, err := generator.Format(tree)
+		if err != nil {
+			return fmt.Errorf("failed to generate tree: %w", err)
+		}
+		if err := atomicfile.Write(output, []byte(formatted), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", dirOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "%s Updated %s\n", time.Now().Format("15:04:05"), dirOutput)
+		return nil
+	}
+
+	if err := render(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Watching %s, press Ctrl+C to stop\n", path)
+	return watch.Watch(ctx, path, watch.Options{Ignore: watchIgnore(generator, output)}, func() {
+		if err := render(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	})
+}
+
+// watchIgnore returns the paths whose changes watchDir doesn't re-render
+// for: the output file it writes, including its temporary files, and hidden
+// and excluded paths, which can't change the tree. Ignored directories aren't
+// watched at all, so excluded trees such as node_modules use no watches.
+func watchIgnore(generator *dir.Generator, output string) func(string) bool {
+	tmpPrefix := "." + filepath.Base(output) + ".tmp-"
+	return func(p string) bool {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return false
+		}
+		base := filepath.Base(abs)
+		if abs == output || strings.HasPrefix(base, tmpPrefix) {
+			return true
+		}
+		return !generator.Shows(base)
+	}
+}
+
+// warnSecrets prints a warning to stderr listing likely secret files, so they
+// are noticed before the payload is pasted into an external service.
+func warnSecrets(secrets []string, allowed bool) {
+	if len(secrets) == 0 {
+		return
+	}
+	action := "contents omitted"
+	if allowed {
+		action = "contents allowed by --allow-secrets"
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %d likely secret file(s) detected (%s): %s\n",
+		len(secrets), action, strings.Join(secrets, ", "))
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jupiterozeye/context/internal/dir"
+)
+
+func TestWatchIgnore(t *testing.T) {
+	root := t.TempDir()
+	output := filepath.Join(root, "tree.txt")
+	ignore := watchIgnore(dir.NewGenerator(dir.Options{Exclude: "node_modules, dist"}), output)
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"tree.txt", true},
+		{".tree.txt.tmp-123", true},
+		{".git", true},
+		{"node_modules", true},
+		{"web/node_modules", true},
+		{"dist", true},
+		{"src", false},
+		{"src/main.go", false},
+	}
+	for _, tt := range tests {
+		if got := ignore(filepath.Join(root, tt.path)); got != tt.want {
+			t.Errorf("ignore(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -2,38 +2,71 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/jupiterozeye/context/internal/clipboard"
 	"github.com/jupiterozeye/context/internal/output"
 	"github.com/spf13/cobra"
 )
 
 var (
-	lastFormat string
-	lastNoCopy bool
+	lastFormat     string
+	lastNoCopy     bool
+	lastFailed     bool
+	lastCwd        string
+	lastHere       bool
+	lastSince      string
+	lastUntil      string
+	lastGrep       string
+	lastOutputGrep string
+	lastExclude    []string
 )
 
 var lastCmd = &cobra.Command{
-	Use:   "last [n]",
+	Use:   "last [n | from..to]",
 	Short: "Show last n commands with their output",
-	Long:  `Show the last n commands with their output from the command logs and copy to clipboard.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runLast,
+	Long: `Show the last n commands with their output from the command logs and copy to clipboard.
+
+A range selects commands by ID instead, as shown by the markdown and detailed
+formats: "context last 3..7" shows IDs 3 to 7, and negative bounds count back
+from the most recent command, so "context last -5..-2" skips the last one.
+
+Filters are applied before counting, so "context last 3 --failed" shows the
+three most recent failed commands.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runLast,
 }
 
 func init() {
 	rootCmd.AddCommand(lastCmd)
 	lastCmd.Flags().StringVarP(&lastFormat, "format", "f", "raw", "Output format: raw|markdown|detailed")
 	lastCmd.Flags().BoolVarP(&lastNoCopy, "no-copy", "c", false, "Print only, don't copy to clipboard")
+	lastCmd.Flags().BoolVar(&lastFailed, "failed", false, "Only commands that exited with a non-zero status")
+	lastCmd.Flags().StringVar(&lastCwd, "cwd", "", "Only commands run in DIR or below it")
+	lastCmd.Flags().BoolVar(&lastHere, "here", false, "Only commands run in the current directory or below it")
+	lastCmd.Flags().StringVar(&lastSince, "since", "", "Only commands started after a time or duration ago (15m, 2h, 1d, 2024-05-01 14:00)")
+	lastCmd.Flags().StringVar(&lastUntil, "until", "", "Only commands started before a time or duration ago")
+	lastCmd.Flags().StringVar(&lastGrep, "grep", "", "Only commands whose text matches REGEX")
+	lastCmd.Flags().StringVar(&lastOutputGrep, "output-grep", "", "Only commands whose output matches REGEX")
+	lastCmd.Flags().StringArrayVar(&lastExclude, "exclude-cmd", nil, "Skip commands matching REGEX (repeatable)")
+	addLimitFlags(lastCmd)
 }
 
 func runLast(cmd *cobra.Command, args []string) error {
 	n := 1
+	var start, end int
+	ranged := false
 	if len(args) > 0 {
 		var err error
-		n, err = strconv.Atoi(args[0])
-		if err != nil {
+		if from, to, ok := strings.Cut(args[0], ".."); ok {
+			if start, end, err = parseRange(from, to); err != nil {
+				return err
+			}
+			ranged = true
+		} else if n, err = strconv.Atoi(args[0]); err != nil {
 			return fmt.Errorf("invalid number: %s", args[0])
 		}
 	}
@@ -42,24 +75,191 @@ func runLast(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("number must be positive")
 	}
 
-	reader := output.NewReader(output.Options{
+	useColor, err := colorEnabled()
+	if err != nil {
+		return err
+	}
+
+	filter, err := lastFilter()
+	if err != nil {
+		return err
+	}
+
+	opts := withLimits(output.Options{
 		Format: lastFormat,
+		Filter: filter,
 	})
+	reader := output.NewReader(opts)
 
-	entries, err := reader.Read(n)
+	var entries []output.LogEntry
+	if ranged {
+		entries, err = reader.ReadAll()
+		entries = output.SelectRange(entries, start, end)
+		if err == nil && len(entries) == 0 {
+			err = fmt.Errorf("no commands in range %s", args[0])
+		}
+	} else {
+		entries, err = reader.Read(n)
+		if err == nil && len(entries) == 0 {
+			err = fmt.Errorf("no commands match the filters")
+		}
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read command output logs: %w", err)
 	}
 
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+	entries = redactEntries(redactor, entries)
+	reportRedactions(redactor)
+
 	formatted := reader.FormatEntries(entries)
-	fmt.Print(formatted)
 
-	if !lastNoCopy {
-		if err := clipboard.Copy(formatted); err != nil {
-			return fmt.Errorf("failed to copy to clipboard: %w", err)
+	display := formatted
+	if useColor {
+		opts.Color = true
+		display = output.NewReader(opts).FormatEntries(entries)
+	}
+
+	return emitStyled(display, formatted, lastNoCopy)
+}
+
+// parseRange reads the bounds of a from..to range. Both must be non-zero and,
+// when they have the same sign, in order.
+func parseRange(from, to string) (int, int, error) {
+	start, err1 := strconv.Atoi(from)
+	end, err2 := strconv.Atoi(to)
+	if err1 != nil || err2 != nil || start == 0 || end == 0 {
+		return 0, 0, fmt.Errorf("invalid range %s..%s: use IDs like 3..7 or offsets like -5..-2", from, to)
+	}
+	if (start > 0) == (end > 0) && start > end {
+		return 0, 0, fmt.Errorf("invalid range %s..%s: start is after end", from, to)
+	}
+	return start, end, nil
+}
+
+// negativeRange matches ranges such as -5..-2 that would otherwise be parsed
+// as shorthand flags
+var negativeRange = regexp.MustCompile(`^-\d+\.\.-?\d+$`)
+
+// rangeArgs moves negative ranges given to last after a "--", so they reach
+// the command as arguments. Only arguments after a "last" subcommand are
+// touched, not a path or command line that happens to be "last".
+func rangeArgs(args []string) []string {
+	// Find the subcommand, skipping the root command's flags and their values
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") && args[i] != "--" {
+		name := strings.TrimLeft(args[i], "-")
+		if flag := rootCmd.PersistentFlags().Lookup(name); flag != nil && flag.Value.Type() != "bool" {
+			i++
 		}
-		fmt.Println("\nCopied to clipboard!")
+		i++
 	}
+	if i >= len(args) || args[i] != "last" {
+		return args
+	}
+
+	rest := append([]string(nil), args[:i+1]...)
+	var ranges []string
+	for _, arg := range args[i+1:] {
+		if arg == "--" {
+			return args
+		}
+		if negativeRange.MatchString(arg) {
+			ranges = append(ranges, arg)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if len(ranges) == 0 {
+		return args
+	}
+	return append(append(rest, "--"), ranges...)
+}
 
-	return nil
+// lastFilter builds the entry filter selected by the last flags.
+func lastFilter() (output.Filter, error) {
+	var filter output.Filter
+	var err error
+
+	filter.Failed = lastFailed
+
+	if lastCwd != "" && lastHere {
+		return filter, fmt.Errorf("--cwd and --here cannot be combined")
+	}
+	dir := lastCwd
+	if lastHere {
+		dir = "."
+	}
+	if dir != "" {
+		if filter.Dir, err = filepath.Abs(dir); err != nil {
+			return filter, err
+		}
+	}
+
+	now := time.Now()
+	if lastSince != "" {
+		if filter.Since, err = parseTimeFlag(lastSince, now); err != nil {
+			return filter, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if lastUntil != "" {
+		if filter.Until, err = parseTimeFlag(lastUntil, now); err != nil {
+			return filter, fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	if lastGrep != "" {
+		if filter.Grep, err = regexp.Compile(lastGrep); err != nil {
+			return filter, fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+	if lastOutputGrep != "" {
+		if filter.OutputGrep, err = regexp.Compile(lastOutputGrep); err != nil {
+			return filter, fmt.Errorf("invalid --output-grep: %w", err)
+		}
+	}
+	for _, pattern := range lastExclude {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return filter, fmt.Errorf("invalid --exclude-cmd: %w", err)
+		}
+		filter.ExcludeCmd = append(filter.ExcludeCmd, re)
+	}
+
+	return filter, nil
+}
+
+// timeLayouts are the absolute times accepted by --since and --until, in
+// local time unless they carry an offset.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseTimeFlag reads a duration before now ("15m", "2h30m", "3d"), a clock
+// time today ("14:30") or a date and time.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("15:04", value, time.Local); err == nil {
+		return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a duration (15m, 2h, 1d) or time (2006-01-02 15:04)", value)
 }
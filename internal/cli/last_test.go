@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jupiterozeye/context/internal/output"
+)
+
+func TestLastNoMatches(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	start := time.Now().Add(-time.Minute)
+	entry := output.LogEntry{Command: "go test ./...", StartTime: start, EndTime: start.Add(time.Second), Output: "ok"}
+	if _, err := output.WriteRecord(filepath.Join(home, ".context", "logs"), entry, "bash"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"last", "-c", "--failed"},
+		{"last", "-c", "--grep", "nomatch"},
+	} {
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), "no commands match the filters") {
+			t.Errorf("%v: err = %v, want no commands match the filters", args, err)
+		}
+		lastFailed, lastGrep = false, ""
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jupiterozeye/context/internal/clipboard"
+	"github.com/jupiterozeye/context/internal/color"
+	"github.com/jupiterozeye/context/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Output limits shared by the commands that print captured commands
+var (
+	maxLines      int
+	maxBytes      int
+	maxTotalBytes int
+)
+
+// addLimitFlags registers the flags that shorten long command output.
+func addLimitFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&maxLines, "max-lines", 0, "Keep the first and last lines of each output, up to N in all (0 = unlimited)")
+	cmd.Flags().IntVar(&maxBytes, "max-bytes", 0, "Keep the start and end of each output, up to N bytes (0 = unlimited)")
+	cmd.Flags().IntVar(&maxTotalBytes, "max-total", 0, "Shorten the largest outputs until the whole payload fits in N bytes (0 = unlimited)")
+}
+
+// withLimits applies the output limit flags to opts.
+func withLimits(opts output.Options) output.Options {
+	opts.MaxLines = maxLines
+	opts.MaxBytes = maxBytes
+	opts.MaxTotalBytes = maxTotalBytes
+	return opts
+}
+
+// colorEnabled reports whether output printed to stdout should be coloured,
+// according to the --color flag, NO_COLOR and whether stdout is a terminal.
+func colorEnabled() (bool, error) {
+	return color.Enabled(colorMode, os.Stdout)
+}
+
+// emit prints output and, unless noCopy is set, copies it to the clipboard.
+func emit(output string, noCopy bool) error {
+	return emitStyled(output, output, noCopy)
+}
+
+// emitStyled prints display, which may contain colour, and copies the plain
+// payload to the clipboard unless noCopy is set.
+func emitStyled(display, payload string, noCopy bool) error {
+	fmt.Print(display)
+
+	if !noCopy {
+		if err := clipboard.Copy(payload); err != nil {
+			return fmt.Errorf("failed to copy to clipboard: %w", err)
+		}
+		fmt.Println("\nCopied to clipboard!")
+	}
+
+	return nil
+}
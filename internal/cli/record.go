@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/jupiterozeye/context/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	recordCommand    string
+	recordStart      string
+	recordDurationMS float64
+	recordExitCode   int
+	recordCwd        string
+	recordOutputFile string
+	recordOffset     int64
+	recordLogDir     string
+	recordShell      string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Save a finished command to the log (used by the shell integration)",
+	Long: `Save a finished command, its exit code and output to the command log read
+by context last. The shell integration calls this after every command; the
+end time is taken as now.`,
+	Args:   cobra.NoArgs,
+	Hidden: true,
+	RunE:   runRecord,
+}
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+	recordCmd.Flags().StringVar(&recordCommand, "command", "", "Command line as typed, may span several lines")
+	recordCmd.Flags().StringVar(&recordStart, "start", "", "Start time as RFC 3339 or Unix seconds with an optional fraction")
+	recordCmd.Flags().Float64Var(&recordDurationMS, "duration-ms", 0, "Run time in milliseconds, used when --start is not given")
+	recordCmd.Flags().IntVar(&recordExitCode, "exit-code", 0, "Exit status of the command")
+	recordCmd.Flags().StringVar(&recordCwd, "cwd", "", "Directory the command ran in (default: current directory)")
+	recordCmd.Flags().StringVar(&recordOutputFile, "output-file", "", "File holding the command's captured output")
+	recordCmd.Flags().Int64Var(&recordOffset, "output-offset", 0, "Read the output file from this byte offset, e.g. where a typescript ended before the command")
+	recordCmd.Flags().StringVar(&recordLogDir, "log-dir", "", "Log directory (default: ~/.context/logs)")
+	recordCmd.Flags().StringVar(&recordShell, "shell", "", "Shell that ran the command")
+	recordCmd.MarkFlagRequired("command")
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	end := time.Now()
+	start := end.Add(-time.Duration(recordDurationMS * float64(time.Millisecond)))
+	if recordStart != "" {
+		var err error
+		if start, err = parseRecordTime(recordStart); err != nil {
+			return err
+		}
+	}
+
+	cwd := recordCwd
+	if cwd == "" {
+		cwd, _ = os.Getwd()
+	}
+
+	var out []byte
+	if recordOutputFile != "" {
+		var err error
+		if out, err = readOutputFile(recordOutputFile, recordOffset); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read output: %w", err)
+		}
+	}
+
+	logDir := recordLogDir
+	if logDir == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("cannot get home directory: %w", err)
+		}
+		logDir = filepath.Join(homeDir, ".context", "logs")
+	}
+
+	_, err := output.WriteRecord(logDir, output.LogEntry{
+		Command:    recordCommand,
+		StartTime:  start,
+		EndTime:    end,
+		Duration:   end.Sub(start),
+		ExitCode:   recordExitCode,
+		WorkingDir: cwd,
+		Output:     string(out),
+	}, recordShell)
+	if err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+	return nil
+}
+
+// readOutputFile reads path from offset to the end. A file that has since
+// been truncated below offset gives no output.
+func readOutputFile(path string, offset int64) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if info, err := file.Stat(); err != nil || info.Size() < offset {
+		return nil, err
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(file)
+}
+
+// parseRecordTime accepts RFC 3339 times and Unix timestamps such as bash's
+// $EPOCHREALTIME ("1712345678.123456").
+func parseRecordTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	secs, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid start time %q", value)
+	}
+	whole, frac := math.Modf(secs)
+	return time.Unix(int64(whole), int64(math.Round(frac*1e9))), nil
+}
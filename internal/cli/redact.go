@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jupiterozeye/context/internal/config"
+	"github.com/jupiterozeye/context/internal/dir"
+	"github.com/jupiterozeye/context/internal/output"
+	"github.com/jupiterozeye/context/internal/redact"
+)
+
+var noRedact bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&noRedact, "no-redact", false, "Keep tokens, keys and passwords in the output instead of redacting them")
+}
+
+// newRedactor returns the redactor configured in ~/.context/config.json, or
+// nil when redaction is turned off.
+func newRedactor() (*redact.Redactor, error) {
+	if noRedact {
+		return nil, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return redact.New(cfg.Redact)
+}
+
+// redactEntries redacts the command lines and outputs of log entries.
+func redactEntries(r *redact.Redactor, entries []output.LogEntry) []output.LogEntry {
+	if r == nil {
+		return entries
+	}
+	redacted := make([]output.LogEntry, len(entries))
+	for i, e := range entries {
+		e.Command = r.Redact(e.Command)
+		e.Output = r.Redact(e.Output)
+		redacted[i] = e
+	}
+	return redacted
+}
+
+// redactTree redacts the file contents included with a tree.
+func redactTree(r *redact.Redactor, tree *dir.Tree) {
+	for i := range tree.KeyFiles {
+		tree.KeyFiles[i].Content = r.Redact(tree.KeyFiles[i].Content)
+	}
+	for i := range tree.Files {
+		tree.Files[i].Content = r.Redact(tree.Files[i].Content)
+	}
+}
+
+// reportRedactions prints to stderr how many items were redacted, so it's
+// clear why the payload differs from the original.
+func reportRedactions(r *redact.Redactor) {
+	if r.Total() == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Redacted %d likely secret(s) (%s), use --no-redact to keep them\n", r.Total(), r.Summary())
+}
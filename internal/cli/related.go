@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/jupiterozeye/context/internal/dir"
+	"github.com/jupiterozeye/context/internal/related"
+	"github.com/spf13/cobra"
+)
+
+var (
+	relatedFormat  string
+	relatedNoCopy  bool
+	relatedSecrets bool
+)
+
+var relatedCmd = &cobra.Command{
+	Use:   "related <file>",
+	Short: "Bundle a Go file with the files needed to understand it",
+	Long: `Collect a Go file together with the rest of its package, its _test.go
+counterparts and the files in other packages of the module that declare the
+identifiers it uses, and copy them as a tree followed by their contents.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRelated,
+}
+
+func init() {
+	rootCmd.AddCommand(relatedCmd)
+	relatedCmd.Flags().StringVarP(&relatedFormat, "format", "f", "tree", "Output format: tree|json|markdown")
+	relatedCmd.Flags().BoolVarP(&relatedNoCopy, "no-copy", "c", false, "Print only, don't copy to clipboard")
+	relatedCmd.Flags().BoolVar(&relatedSecrets, "allow-secrets", false, "Include contents of files that look like secrets (.env, keys, credentials)")
+}
+
+func runRelated(cmd *cobra.Command, args []string) error {
+	files, err := related.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	generator := dir.NewGenerator(dir.Options{
+		Format:       relatedFormat,
+		AllowSecrets: relatedSecrets,
+	})
+
+	paths := files.All()
+	tree, err := generator.WalkPaths(files.Root, paths)
+	if err != nil {
+		return fmt.Errorf("failed to collect related files: %w", err)
+	}
+	tree.Files = generator.ReadFiles(files.Root, paths)
+
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+	redactTree(redactor, tree)
+
+	warnSecrets(tree.Secrets, relatedSecrets)
+	reportRedactions(redactor)
+
+	output, err := generator.Format(tree)
+	if err != nil {
+		return fmt.Errorf("failed to collect related files: %w", err)
+	}
+
+	return emit(output, relatedNoCopy)
+}
@@ -1,9 +1,18 @@
 package cli
 
 import (
+	"errors"
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
+var colorMode string
+
+// ErrReported is wrapped by errors whose details the command has already
+// printed, so only the exit status is left to set.
+var ErrReported = errors.New("already reported")
+
 var rootCmd = &cobra.Command{
 	Use:   "context",
 	Short: "Terminal context capture tool for AI-assisted debugging",
@@ -14,12 +23,22 @@ automatically copying them to your clipboard for easy sharing.
 
 Usage:
   context dir [path]     - Generate directory tree and copy to clipboard
-  context last [n]       - Show last n commands from shell history`,
+  context deps [path]    - Summarize dependency manifests
+  context related <file> - Bundle a Go file with the files it depends on
+  context scaffold [path] - Create the directories and files of a pasted tree
+  context last [n]       - Show last n commands from shell history
+  context show <id>...   - Show captured commands by ID
+  context search <query> - Search captured commands and their output`,
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Colorize terminal output: auto|always|never")
+}
+
 func Execute() error {
+	rootCmd.SetArgs(rangeArgs(os.Args[1:]))
 	return rootCmd.Execute()
 }
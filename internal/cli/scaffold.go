@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jupiterozeye/context/internal/clipboard"
+	"github.com/jupiterozeye/context/internal/dir"
+	"github.com/jupiterozeye/context/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+var scaffoldDryRun bool
+
+var scaffoldCmd = &cobra.Command{
+	Use:   "scaffold [path]",
+	Short: "Create directories and empty files from a tree",
+	Long: `Read a directory tree in the tree, markdown or JSON format of context dir
+from stdin, or from the clipboard when stdin is a terminal, and create its
+directories and empty files under the specified path (or the current
+directory). Existing files are never overwritten.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runScaffold,
+}
+
+func init() {
+	rootCmd.AddCommand(scaffoldCmd)
+	scaffoldCmd.Flags().BoolVarP(&scaffoldDryRun, "dry-run", "n", false, "Show what would be created without creating anything")
+}
+
+func runScaffold(cmd *cobra.Command, args []string) error {
+	target := "."
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	input, err := readScaffoldInput()
+	if err != nil {
+		return err
+	}
+
+	paths, err := dir.ParsePaths(input)
+	if err != nil {
+		return fmt.Errorf("failed to parse tree: %w", err)
+	}
+
+	actions, err := scaffold.Plan(target, paths)
+	if err != nil {
+		return err
+	}
+
+	verb := "create"
+	if scaffoldDryRun {
+		verb = "would create"
+	}
+	created := 0
+	for _, a := range actions {
+		if a.Exists {
+			fmt.Printf("exists        %s\n", a.Path)
+			continue
+		}
+		created++
+		fmt.Printf("%-13s %s\n", verb, a.Path)
+	}
+
+	if scaffoldDryRun {
+		fmt.Printf("\n%d to create, %d existing (dry run, nothing changed)\n", created, len(actions)-created)
+		return nil
+	}
+	if err := scaffold.Apply(target, actions); err != nil {
+		return fmt.Errorf("failed to scaffold: %w", err)
+	}
+	fmt.Printf("\nCreated %d, left %d existing untouched\n", created, len(actions)-created)
+	return nil
+}
+
+// readScaffoldInput reads the tree from stdin when something is piped in,
+// and from the clipboard otherwise.
+func readScaffoldInput() (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	text, err := clipboard.Paste()
+	if err != nil {
+		return "", fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	return text, nil
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jupiterozeye/context/internal/output"
+	"github.com/jupiterozeye/context/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var (
+	searchFormat  string
+	searchNoCopy  bool
+	searchCopy    string
+	searchRegex   bool
+	searchCase    bool
+	searchContext int
+	searchLimit   int
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search captured commands and their output",
+	Long: `Search the command lines and output of every retained command log and the
+typescript, newest first, showing the matching lines with some surrounding
+context. Each hit is numbered; --copy 1,3 copies those commands in full, in
+the chosen format.
+
+The query is matched as plain, case-insensitive text unless --regex or
+--case-sensitive is given.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().StringVar(&searchCopy, "copy", "", "Copy the hits with these numbers (e.g. 1,3-4 or all) instead of listing")
+	searchCmd.Flags().StringVarP(&searchFormat, "format", "f", "raw", "Format of copied hits: raw|markdown|detailed")
+	searchCmd.Flags().BoolVarP(&searchNoCopy, "no-copy", "c", false, "With --copy, print only, don't copy to clipboard")
+	searchCmd.Flags().BoolVarP(&searchRegex, "regex", "E", false, "Treat the query as a regular expression")
+	searchCmd.Flags().BoolVarP(&searchCase, "case-sensitive", "s", false, "Match case exactly")
+	searchCmd.Flags().IntVarP(&searchContext, "context", "C", 2, "Lines of output to show around each match")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of hits to list (0 = all)")
+	addLimitFlags(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	pattern := strings.Join(args, " ")
+	if !searchRegex {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if !searchCase {
+		pattern = "(?i)" + pattern
+	}
+	query, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	useColor, err := colorEnabled()
+	if err != nil {
+		return err
+	}
+
+	reader := output.NewReader(withLimits(output.Options{Format: searchFormat, Color: useColor}))
+	hits, err := reader.Search(query, output.SearchOptions{Context: searchContext, Snippets: 3})
+	if err != nil {
+		return fmt.Errorf("failed to read command output logs: %w", err)
+	}
+	if len(hits) == 0 {
+		return fmt.Errorf("no commands match %q", strings.Join(args, " "))
+	}
+
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+
+	if searchCopy == "" {
+		shown := hits
+		if searchLimit > 0 && len(shown) > searchLimit {
+			shown = shown[:searchLimit]
+		}
+		shown = redactHits(redactor, shown)
+		reportRedactions(redactor)
+		fmt.Print(reader.FormatHits(shown, query))
+		if len(shown) < len(hits) {
+			fmt.Fprintf(os.Stderr, "%d of %d hits shown, use --limit to see more\n", len(shown), len(hits))
+		}
+		fmt.Fprintln(os.Stderr, "Copy hits with --copy N[,N...]")
+		return nil
+	}
+
+	numbers, err := parseHitNumbers(searchCopy, len(hits))
+	if err != nil {
+		return err
+	}
+	entries := make([]output.LogEntry, 0, len(numbers))
+	for _, n := range numbers {
+		entries = append(entries, hits[n-1].Entry)
+	}
+	entries = redactEntries(redactor, entries)
+	reportRedactions(redactor)
+
+	plain := output.NewReader(withLimits(output.Options{Format: searchFormat}))
+	formatted := plain.FormatEntries(entries)
+
+	display := formatted
+	if useColor {
+		display = reader.FormatEntries(entries)
+	}
+
+	return emitStyled(display, formatted, searchNoCopy)
+}
+
+// redactHits redacts the command lines and snippets of search hits.
+func redactHits(r *redact.Redactor, hits []output.Hit) []output.Hit {
+	if r == nil {
+		return hits
+	}
+	redacted := make([]output.Hit, len(hits))
+	for i, hit := range hits {
+		hit.Entry.Command = r.Redact(hit.Entry.Command)
+		snippets := make([]output.Snippet, len(hit.Snippets))
+		for j, s := range hit.Snippets {
+			lines := make([]string, len(s.Lines))
+			for k, line := range s.Lines {
+				lines[k] = r.Redact(line)
+			}
+			snippets[j] = output.Snippet{Start: s.Start, Lines: lines}
+		}
+		hit.Snippets = snippets
+		redacted[i] = hit
+	}
+	return redacted
+}
+
+// parseHitNumbers reads a list such as "1,3-4" or "all" of hit numbers
+// between 1 and total.
+func parseHitNumbers(list string, total int) ([]int, error) {
+	if list == "all" {
+		numbers := make([]int, total)
+		for i := range numbers {
+			numbers[i] = i + 1
+		}
+		return numbers, nil
+	}
+
+	var numbers []int
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		if err1 != nil || err2 != nil || start < 1 || end < start {
+			return nil, fmt.Errorf("invalid hit number %q", part)
+		}
+		if end > total {
+			return nil, fmt.Errorf("hit %d doesn't exist, there are %d", end, total)
+		}
+		for n := start; n <= end; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	return numbers, nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jupiterozeye/context/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	showFormat string
+	showNoCopy bool
+)
+
+var showCmd = &cobra.Command{
+	Use:   "show <id>...",
+	Short: "Show captured commands by ID",
+	Long: `Show the captured commands with the given IDs, in the order given, and copy
+them to the clipboard. IDs are shown by "context last --format detailed" and
+"--format markdown"; ranges such as 3..7 select the commands with IDs in that
+range.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runShow,
+}
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+	showCmd.Flags().StringVarP(&showFormat, "format", "f", "raw", "Output format: raw|markdown|detailed")
+	showCmd.Flags().BoolVarP(&showNoCopy, "no-copy", "c", false, "Print only, don't copy to clipboard")
+	addLimitFlags(showCmd)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	useColor, err := colorEnabled()
+	if err != nil {
+		return err
+	}
+
+	opts := withLimits(output.Options{Format: showFormat})
+	reader := output.NewReader(opts)
+	all, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	var entries []output.LogEntry
+	for _, arg := range args {
+		arg = strings.TrimPrefix(arg, "#")
+		if from, to, ok := strings.Cut(arg, ".."); ok {
+			start, err1 := strconv.Atoi(from)
+			end, err2 := strconv.Atoi(to)
+			if err1 != nil || err2 != nil || start <= 0 || end < start {
+				return fmt.Errorf("invalid ID range: %s", arg)
+			}
+			// Like last, a range selects the commands that exist within it.
+			selected := output.SelectRange(all, start, end)
+			if len(selected) == 0 {
+				return fmt.Errorf("no commands in range %s", arg)
+			}
+			entries = append(entries, selected...)
+			continue
+		}
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			return fmt.Errorf("invalid ID: %s", arg)
+		}
+		selected, err := output.SelectIDs(all, []int{id})
+		if err != nil {
+			return err
+		}
+		entries = append(entries, selected...)
+	}
+
+	redactor, err := newRedactor()
+	if err != nil {
+		return err
+	}
+	entries = redactEntries(redactor, entries)
+	reportRedactions(redactor)
+
+	formatted := reader.FormatEntries(entries)
+
+	display := formatted
+	if useColor {
+		opts.Color = true
+		display = output.NewReader(opts).FormatEntries(entries)
+	}
+
+	return emitStyled(display, formatted, showNoCopy)
+}
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jupiterozeye/context/internal/output"
+)
+
+func TestShowRange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	logDir := filepath.Join(home, ".context", "logs")
+	start := time.Now().Add(-time.Hour)
+	for i, command := range []string{"make", "make test", "git status"} {
+		at := start.Add(time.Duration(i) * time.Minute)
+		entry := output.LogEntry{Command: command, StartTime: at, EndTime: at.Add(time.Second), Output: "ok"}
+		if _, err := output.WriteRecord(logDir, entry, "bash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A huge range is bounded by the commands that exist.
+	rootCmd.SetArgs([]string{"show", "-c", "2..2000000000"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Errorf("show 2..2000000000: %v", err)
+	}
+
+	rootCmd.SetArgs([]string{"show", "-c", "10..2000000000"})
+	if err := rootCmd.Execute(); err == nil || !strings.Contains(err.Error(), "no commands in range") {
+		t.Errorf("show 10..2000000000: err = %v, want no commands in range", err)
+	}
+}
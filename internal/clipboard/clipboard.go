@@ -81,4 +81,33 @@ func copyWindows(text string) error {
 	stdin.Write([]byte(text))
 	stdin.Close()
 	return cmd.Wait()
-}
\ No newline at end of file
+}
+func Paste() (string, error) {
+	text, err := clipboard.ReadAll()
+	if err == nil {
+		return text, nil
+	}
+	return pasteFallback()
+}
+
+func pasteFallback() (string, error) {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "linux":
+		candidates = [][]string{{"xclip", "-selection", "clipboard", "-o"}, {"wl-paste", "--no-newline"}}
+	case "darwin":
+		candidates = [][]string{{"pbpaste"}}
+	case "windows":
+		candidates = [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard -Raw"}}
+	default:
+		return "", fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+
+	for _, args := range candidates {
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err == nil {
+			return string(out), nil
+		}
+	}
+	return "", fmt.Errorf("no clipboard utility found (install xclip or wl-clipboard)")
+}
@@ -0,0 +1,115 @@
+package color
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SGR codes used outside of LS_COLORS
+const (
+	Red    = "31"
+	Green  = "32"
+	Yellow = "33"
+	Bold   = "1"
+)
+
+// Enabled reports whether colour should be used for f given a --color mode
+// of auto, always or never. In auto mode colour is used only when f is a
+// terminal and neither NO_COLOR nor TERM=dumb is set.
+func Enabled(mode string, f *os.File) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		return isTerminal(f), nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q (want auto, always or never)", mode)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Paint wraps text in the given SGR code. An empty code leaves text as is.
+func Paint(code, text string) string {
+	if code == "" || text == "" {
+		return text
+	}
+	return "\x1b[" + code + "m" + text + "\x1b[0m"
+}
+
+// Palette maps files to colours following LS_COLORS
+type Palette struct {
+	dir  string
+	link string
+	exec string
+	file string
+	ext  map[string]string
+}
+
+// NewPalette builds a palette from LS_COLORS, falling back to the GNU ls
+// defaults for directories, symlinks and executables.
+func NewPalette() *Palette {
+	p := &Palette{
+		dir:  "01;34",
+		link: "01;36",
+		exec: "01;32",
+		ext:  make(map[string]string),
+	}
+
+	for _, item := range strings.Split(os.Getenv("LS_COLORS"), ":") {
+		key, code, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		switch {
+		case key == "di":
+			p.dir = code
+		case key == "ln":
+			if code != "target" {
+				p.link = code
+			}
+		case key == "ex":
+			p.exec = code
+		case key == "fi":
+			p.file = code
+		case strings.HasPrefix(key, "*"):
+			p.ext[strings.ToLower(key[1:])] = code
+		}
+	}
+
+	return p
+}
+
+// File returns the colour code for a file with the given name and mode
+func (p *Palette) File(name string, mode os.FileMode) string {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return p.link
+	case mode.IsDir():
+		return p.dir
+	case mode&0o111 != 0:
+		return p.exec
+	}
+
+	lower := strings.ToLower(name)
+	if code, ok := p.ext[lower]; ok {
+		return code
+	}
+	if code, ok := p.ext[filepath.Ext(lower)]; ok {
+		return code
+	}
+	return p.file
+}
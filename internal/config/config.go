@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is the user configuration in ~/.context/config.json
+type Config struct {
+	Redact Redact `json:"redact"`
+}
+
+// Redact configures how secrets are removed from payloads
+type Redact struct {
+	Disabled       bool         `json:"disabled,omitempty"`        // turn redaction off entirely
+	Rules          []RedactRule `json:"rules,omitempty"`           // extra patterns to redact
+	Allow          []string     `json:"allow,omitempty"`           // regexes for detected values to keep
+	DisableBuiltin []string     `json:"disable_builtin,omitempty"` // names of built-in detectors to skip
+}
+
+// RedactRule is a user-defined pattern. If it has a capture group only the
+// first group is redacted, so "token: (\S+)" keeps the key.
+type RedactRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// Path returns the location of the config file: $CONTEXT_CONFIG, or
+// ~/.context/config.json.
+func Path() (string, error) {
+	if path := os.Getenv("CONTEXT_CONFIG"); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".context", "config.json"), nil
+}
+
+// Load reads the config file. A missing file gives the defaults.
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
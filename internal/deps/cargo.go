@@ -0,0 +1,76 @@
+package deps
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func parseCargo(dir string) (*Manifest, error) {
+	doc, err := parseTOML(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: "Cargo.toml", Ecosystem: "rust"}
+
+	if pkg := doc["package"]; pkg != nil {
+		m.Name = pkg["name"].str
+		if v := pkg["edition"].str; v != "" {
+			m.Toolchains = append(m.Toolchains, Toolchain{Name: "edition", Version: v})
+		}
+		if v := pkg["rust-version"].str; v != "" {
+			m.Toolchains = append(m.Toolchains, Toolchain{Name: "rust", Version: v})
+		}
+	}
+
+	if ws := doc["workspace"]; ws != nil {
+		m.Workspace = ws["members"].list
+	}
+
+	addCargoDeps(m, doc["dependencies"], "")
+	addCargoDeps(m, doc["workspace.dependencies"], "")
+	addCargoDeps(m, doc["dev-dependencies"], "dev")
+	addCargoDeps(m, doc["build-dependencies"], "build")
+
+	// [patch.<registry>] and [replace] tables
+	var tables []string
+	for table := range doc {
+		if strings.HasPrefix(table, "patch.") || table == "replace" {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		for _, name := range sortedValueKeys(doc[table]) {
+			m.Overrides = append(m.Overrides, Override{From: name, To: describeSource(doc[table][name].table)})
+		}
+	}
+
+	if fileExists(filepath.Join(dir, "Cargo.lock")) {
+		m.Lockfile = "Cargo.lock"
+	}
+
+	return m, nil
+}
+
+func addCargoDeps(m *Manifest, deps map[string]tomlValue, kind string) {
+	for _, name := range sortedValueKeys(deps) {
+		value := deps[name]
+		version := value.str
+		if value.table != nil {
+			version = value.table["version"]
+			if version == "" {
+				version = describeSource(value.table)
+			}
+		}
+		// Dotted keys such as `serde.workspace = true` name the crate first.
+		if crate, attr, ok := strings.Cut(name, "."); ok {
+			name = crate
+			if attr != "version" {
+				version = attr + " " + version
+			}
+		}
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: version, Kind: kind})
+	}
+}
@@ -0,0 +1,314 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Options for summarising dependency manifests
+type Options struct {
+	Format   string // tree, json, markdown; also dot and mermaid for import graphs
+	External bool   // include imports of other modules in import graphs
+}
+
+// Dependency is a single declared dependency
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind,omitempty"` // dev, build, peer, optional
+}
+
+// Override is a replace/override/patch directive
+type Override struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Toolchain is a language or tool version requirement
+type Toolchain struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Manifest is the summary of a single manifest file
+type Manifest struct {
+	Path         string       `json:"path"`
+	Ecosystem    string       `json:"ecosystem"`
+	Name         string       `json:"name,omitempty"`
+	Toolchains   []Toolchain  `json:"toolchains,omitempty"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+	Indirect     int          `json:"indirect,omitempty"`
+	Overrides    []Override   `json:"overrides,omitempty"`
+	Workspace    []string     `json:"workspace,omitempty"`
+	Lockfile     string       `json:"lockfile,omitempty"`
+}
+
+// Summary is the set of manifests found for a project
+type Summary struct {
+	Root      string     `json:"root"`
+	Manifests []Manifest `json:"manifests"`
+}
+
+// parser parses one kind of manifest found in dir
+type parser struct {
+	file  string
+	parse func(dir string) (*Manifest, error)
+}
+
+var parsers = []parser{
+	{"go.work", parseGoWork},
+	{"go.mod", parseGoMod},
+	{"package.json", parsePackageJSON},
+	{"pyproject.toml", parsePyproject},
+	{"requirements.txt", parseRequirements},
+	{"Cargo.toml", parseCargo},
+}
+
+// Generator finds and summarises dependency manifests
+type Generator struct {
+	opts Options
+}
+
+// NewGenerator creates a new manifest summariser
+func NewGenerator(opts Options) *Generator {
+	return &Generator{opts: opts}
+}
+
+// Generate summarises the manifests found from startPath and formats them
+func (g *Generator) Generate(startPath string) (string, error) {
+	summary, err := g.Summarize(startPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch g.opts.Format {
+	case "json":
+		return g.formatJSON(summary)
+	case "markdown":
+		return g.formatMarkdown(summary), nil
+	default: // "tree" or anything else
+		return g.formatTree(summary), nil
+	}
+}
+
+// Summarize looks for manifests in startPath, walking up to the nearest
+// ancestor directory that has any, and parses them.
+func (g *Generator) Summarize(startPath string) (*Summary, error) {
+	abs, err := filepath.Abs(startPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", startPath, err)
+	}
+
+	root := findManifestRoot(abs)
+	if root == "" {
+		return nil, fmt.Errorf("no dependency manifests found in %s or its parents", abs)
+	}
+
+	summary := &Summary{Root: root}
+	for _, p := range parsers {
+		if !fileExists(filepath.Join(root, p.file)) {
+			continue
+		}
+		m, err := p.parse(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", p.file, err)
+		}
+		summary.Manifests = append(summary.Manifests, *m)
+	}
+
+	return summary, nil
+}
+
+// findManifestRoot returns the first of dir and its ancestors that contains a
+// known manifest, or "" if there is none.
+func findManifestRoot(dir string) string {
+	for {
+		for _, p := range parsers {
+			if fileExists(filepath.Join(dir, p.file)) {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// directCount returns the number of dependencies that are not dev/build-only.
+func (m Manifest) directCount() int {
+	n := 0
+	for _, d := range m.Dependencies {
+		if d.Kind == "" {
+			n++
+		}
+	}
+	return n
+}
+
+func (g *Generator) formatJSON(summary *Summary) (string, error) {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false) // keep version ranges like ">=18" readable
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// node is a line in the tree rendering of a summary
+type node struct {
+	label    string
+	children []node
+}
+
+func (g *Generator) formatTree(summary *Summary) string {
+	var result strings.Builder
+	result.WriteString(filepath.Base(summary.Root) + "/\n")
+	nodes := make([]node, 0, len(summary.Manifests))
+	for _, m := range summary.Manifests {
+		nodes = append(nodes, manifestNode(m))
+	}
+	writeNodes(&result, nodes, "")
+	return result.String()
+}
+
+func manifestNode(m Manifest) node {
+	n := node{label: m.Path + " (" + m.Ecosystem + ")"}
+
+	if m.Name != "" {
+		n.children = append(n.children, node{label: "name: " + m.Name})
+	}
+	for _, t := range m.Toolchains {
+		n.children = append(n.children, node{label: t.Name + " " + t.Version})
+	}
+
+	if len(m.Dependencies) > 0 {
+		label := fmt.Sprintf("dependencies (%d direct", m.directCount())
+		if other := len(m.Dependencies) - m.directCount(); other > 0 {
+			label += fmt.Sprintf(", %d dev/optional", other)
+		}
+		if m.Indirect > 0 {
+			label += fmt.Sprintf(", %d indirect", m.Indirect)
+		}
+		deps := node{label: label + ")"}
+		for _, d := range m.Dependencies {
+			deps.children = append(deps.children, node{label: d.label()})
+		}
+		n.children = append(n.children, deps)
+	}
+
+	if len(m.Overrides) > 0 {
+		overrides := node{label: "overrides"}
+		for _, o := range m.Overrides {
+			overrides.children = append(overrides.children, node{label: o.From + " => " + o.To})
+		}
+		n.children = append(n.children, overrides)
+	}
+
+	if len(m.Workspace) > 0 {
+		ws := node{label: "workspace"}
+		for _, w := range m.Workspace {
+			ws.children = append(ws.children, node{label: w})
+		}
+		n.children = append(n.children, ws)
+	}
+
+	if m.Lockfile != "" {
+		n.children = append(n.children, node{label: "lockfile: " + m.Lockfile})
+	}
+
+	return n
+}
+
+func (d Dependency) label() string {
+	label := d.Name
+	if d.Version != "" {
+		label += " " + d.Version
+	}
+	if d.Kind != "" {
+		label += " (" + d.Kind + ")"
+	}
+	return label
+}
+
+func writeNodes(result *strings.Builder, nodes []node, prefix string) {
+	for i, n := range nodes {
+		isLast := i == len(nodes)-1
+
+		connector := "├── "
+		extension := "│   "
+		if isLast {
+			connector = "└── "
+			extension = "    "
+		}
+
+		result.WriteString(prefix + connector + n.label + "\n")
+		writeNodes(result, n.children, prefix+extension)
+	}
+}
+
+func (g *Generator) formatMarkdown(summary *Summary) string {
+	var result strings.Builder
+	result.WriteString("# Dependencies: " + filepath.Base(summary.Root) + "\n")
+
+	for _, m := range summary.Manifests {
+		result.WriteString("\n## " + m.Path + " (" + m.Ecosystem + ")\n\n")
+
+		if m.Name != "" {
+			result.WriteString("- **Name:** `" + m.Name + "`\n")
+		}
+		for _, t := range m.Toolchains {
+			result.WriteString("- **" + t.Name + ":** `" + t.Version + "`\n")
+		}
+		if m.Indirect > 0 {
+			result.WriteString(fmt.Sprintf("- **Indirect dependencies:** %d\n", m.Indirect))
+		}
+		if m.Lockfile != "" {
+			result.WriteString("- **Lockfile:** " + m.Lockfile + "\n")
+		}
+
+		if len(m.Dependencies) > 0 {
+			result.WriteString("\n| Dependency | Version | Kind |\n")
+			result.WriteString("|---|---|---|\n")
+			for _, d := range m.Dependencies {
+				kind := d.Kind
+				if kind == "" {
+					kind = "direct"
+				}
+				result.WriteString("| `" + escapeCell(d.Name) + "` | " + escapeCell(d.Version) + " | " + kind + " |\n")
+			}
+		}
+
+		if len(m.Overrides) > 0 {
+			result.WriteString("\n**Overrides:**\n\n")
+			for _, o := range m.Overrides {
+				result.WriteString("- `" + o.From + "` => `" + o.To + "`\n")
+			}
+		}
+
+		if len(m.Workspace) > 0 {
+			result.WriteString("\n**Workspace:**\n\n")
+			for _, w := range m.Workspace {
+				result.WriteString("- `" + w + "`\n")
+			}
+		}
+	}
+
+	return result.String()
+}
+
+// escapeCell escapes pipes, as in "^18 || ^19", so they don't end a table cell.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
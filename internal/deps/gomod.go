@@ -0,0 +1,178 @@
+package deps
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// goDirective is a single directive from a go.mod or go.work file, with block
+// directives such as "require ( ... )" flattened into one per line.
+type goDirective struct {
+	verb    string
+	args    []string
+	comment string
+}
+
+// parseGoDirectives reads the directives of a go.mod-style file.
+func parseGoDirectives(path string) ([]goDirective, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var directives []goDirective
+	block := ""
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		comment := ""
+		if i := strings.Index(line, "//"); i >= 0 {
+			comment = strings.TrimSpace(line[i+2:])
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		if block != "" {
+			if line == ")" {
+				block = ""
+				continue
+			}
+			directives = append(directives, goDirective{verb: block, args: goFields(line), comment: comment})
+			continue
+		}
+
+		fields := goFields(line)
+		if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		directives = append(directives, goDirective{verb: fields[0], args: fields[1:], comment: comment})
+	}
+
+	return directives, scanner.Err()
+}
+
+// goFields splits a directive line into fields, unquoting quoted paths.
+func goFields(line string) []string {
+	fields := strings.Fields(line)
+	for i, f := range fields {
+		fields[i] = strings.Trim(f, "\"`")
+	}
+	return fields
+}
+
+func parseGoMod(dir string) (*Manifest, error) {
+	directives, err := parseGoDirectives(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: "go.mod", Ecosystem: "go"}
+
+	for _, d := range directives {
+		switch d.verb {
+		case "module":
+			if len(d.args) > 0 {
+				m.Name = d.args[0]
+			}
+		case "go":
+			if len(d.args) > 0 {
+				m.Toolchains = append(m.Toolchains, Toolchain{Name: "go", Version: d.args[0]})
+			}
+		case "toolchain":
+			if len(d.args) > 0 {
+				m.Toolchains = append(m.Toolchains, Toolchain{Name: "toolchain", Version: d.args[0]})
+			}
+		case "require":
+			if len(d.args) < 2 {
+				continue
+			}
+			if d.comment == "indirect" || strings.HasPrefix(d.comment, "indirect;") {
+				m.Indirect++
+				continue
+			}
+			m.Dependencies = append(m.Dependencies, Dependency{Name: d.args[0], Version: d.args[1]})
+		case "replace":
+			if o, ok := parseGoReplace(d.args); ok {
+				m.Overrides = append(m.Overrides, o)
+			}
+		}
+	}
+
+	if fileExists(filepath.Join(dir, "go.sum")) {
+		n, err := countGoSumModules(filepath.Join(dir, "go.sum"))
+		if err == nil {
+			m.Lockfile = fmt.Sprintf("go.sum (%d modules)", n)
+		}
+	}
+
+	return m, nil
+}
+
+// parseGoReplace parses "old [v] => new [v]" replace arguments.
+func parseGoReplace(args []string) (Override, bool) {
+	for i, arg := range args {
+		if arg == "=>" {
+			return Override{
+				From: strings.Join(args[:i], " "),
+				To:   strings.Join(args[i+1:], " "),
+			}, i > 0 && i < len(args)-1
+		}
+	}
+	return Override{}, false
+}
+
+// countGoSumModules counts the distinct module versions listed in a go.sum.
+func countGoSumModules(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		seen[fields[0]+" "+strings.TrimSuffix(fields[1], "/go.mod")] = true
+	}
+
+	return len(seen), scanner.Err()
+}
+
+func parseGoWork(dir string) (*Manifest, error) {
+	directives, err := parseGoDirectives(filepath.Join(dir, "go.work"))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: "go.work", Ecosystem: "go"}
+
+	for _, d := range directives {
+		switch d.verb {
+		case "go", "toolchain":
+			if len(d.args) > 0 {
+				m.Toolchains = append(m.Toolchains, Toolchain{Name: d.verb, Version: d.args[0]})
+			}
+		case "use":
+			m.Workspace = append(m.Workspace, d.args...)
+		case "replace":
+			if o, ok := parseGoReplace(d.args); ok {
+				m.Overrides = append(m.Overrides, o)
+			}
+		}
+	}
+
+	return m, nil
+}
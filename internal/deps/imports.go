@@ -0,0 +1,272 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Package is a Go package in the module and the packages it imports
+type Package struct {
+	Path     string   `json:"path"`               // relative to the module root, "." for the root package
+	Imports  []string `json:"imports,omitempty"`  // module packages, relative like Path
+	External []string `json:"external,omitempty"` // required modules, with Options.External
+}
+
+// Graph is the import graph of the packages in a Go module
+type Graph struct {
+	Root     string    `json:"root"`
+	Module   string    `json:"module"`
+	Packages []Package `json:"packages"`
+}
+
+// ImportGraph parses the non-test Go files of the module containing
+// startPath and returns the imports between its packages. Standard library
+// imports are left out; imports of other modules are included with
+// Options.External, grouped by the required module that provides them.
+func (g *Generator) ImportGraph(startPath string) (*Graph, error) {
+	abs, err := filepath.Abs(startPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", startPath, err)
+	}
+
+	root := abs
+	for !fileExists(filepath.Join(root, "go.mod")) {
+		parent := filepath.Dir(root)
+		if parent == root {
+			return nil, fmt.Errorf("no go.mod found in %s or its parents", abs)
+		}
+		root = parent
+	}
+
+	directives, err := parseGoDirectives(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse go.mod: %w", err)
+	}
+	graph := &Graph{Root: root}
+	var required []string
+	for _, d := range directives {
+		switch {
+		case d.verb == "module" && len(d.args) > 0:
+			graph.Module = d.args[0]
+		case d.verb == "require" && len(d.args) > 0:
+			required = append(required, d.args[0])
+		}
+	}
+	if graph.Module == "" {
+		return nil, fmt.Errorf("go.mod in %s has no module directive", root)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root {
+			// Same rules as the go tool, plus nested modules.
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") ||
+				strings.HasPrefix(name, "_") || fileExists(filepath.Join(path, "go.mod")) {
+				return filepath.SkipDir
+			}
+		}
+
+		pkg, ok := g.readPackage(path, graph.Module, required)
+		if ok {
+			rel, _ := filepath.Rel(root, path)
+			pkg.Path = filepath.ToSlash(rel)
+			graph.Packages = append(graph.Packages, pkg)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(graph.Packages, func(i, j int) bool {
+		return graph.Packages[i].Path < graph.Packages[j].Path
+	})
+	return graph, nil
+}
+
+// readPackage collects the imports of the Go files in dir, reporting false if
+// there are none.
+func (g *Generator) readPackage(dir, module string, required []string) (Package, bool) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return Package{}, false
+	}
+
+	var pkg Package
+	found := false
+	internal := make(map[string]bool)
+	external := make(map[string]bool)
+	fset := token.NewFileSet()
+
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := goparser.ParseFile(fset, filepath.Join(dir, name), nil, goparser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		found = true
+
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case path == module:
+				internal["."] = true
+			case strings.HasPrefix(path, module+"/"):
+				internal[strings.TrimPrefix(path, module+"/")] = true
+			case g.opts.External && !isStdlib(path):
+				external[moduleFor(path, required)] = true
+			}
+		}
+	}
+
+	pkg.Imports = sortedKeys(internal)
+	pkg.External = sortedKeys(external)
+	return pkg, found
+}
+
+// isStdlib reports whether an import path belongs to the standard library,
+// whose first path element never contains a dot.
+func isStdlib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// moduleFor returns the required module providing an import path, or the
+// path itself if go.mod doesn't list one.
+func moduleFor(path string, required []string) string {
+	best := ""
+	for _, m := range required {
+		if (path == m || strings.HasPrefix(path, m+"/")) && len(m) > len(best) {
+			best = m
+		}
+	}
+	if best == "" {
+		return path
+	}
+	return best
+}
+
+// GenerateGraph builds the import graph of the module containing startPath
+// and formats it as text, dot, mermaid, markdown or json.
+func (g *Generator) GenerateGraph(startPath string) (string, error) {
+	graph, err := g.ImportGraph(startPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch g.opts.Format {
+	case "json":
+		var buf strings.Builder
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(graph); err != nil {
+			return "", fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		return buf.String(), nil
+	case "dot":
+		return formatGraphDOT(graph), nil
+	case "mermaid":
+		return formatGraphMermaid(graph), nil
+	case "markdown":
+		return "# Import Graph: " + graph.Module + "\n\n```mermaid\n" + formatGraphMermaid(graph) + "```\n", nil
+	case "tree", "text", "":
+		return formatGraphText(graph), nil
+	default:
+		return "", fmt.Errorf("invalid graph format %q (want text, dot, mermaid, markdown or json)", g.opts.Format)
+	}
+}
+
+func formatGraphText(graph *Graph) string {
+	var result strings.Builder
+	result.WriteString("Import graph for " + graph.Module + ":\n\n")
+	for _, p := range graph.Packages {
+		targets := append(append([]string{}, p.Imports...), p.External...)
+		if len(targets) == 0 {
+			result.WriteString(p.Path + "\n")
+			continue
+		}
+		result.WriteString(p.Path + " -> " + strings.Join(targets, ", ") + "\n")
+	}
+	return result.String()
+}
+
+func formatGraphDOT(graph *Graph) string {
+	var result strings.Builder
+	result.WriteString("digraph imports {\n")
+	result.WriteString("  rankdir=LR;\n")
+	result.WriteString("  node [shape=box];\n")
+
+	external := make(map[string]bool)
+	for _, p := range graph.Packages {
+		result.WriteString(fmt.Sprintf("  %q;\n", p.Path))
+		for _, e := range p.External {
+			external[e] = true
+		}
+	}
+	for _, e := range sortedKeys(external) {
+		result.WriteString(fmt.Sprintf("  %q [style=dashed];\n", e))
+	}
+	for _, p := range graph.Packages {
+		for _, i := range p.Imports {
+			result.WriteString(fmt.Sprintf("  %q -> %q;\n", p.Path, i))
+		}
+		for _, e := range p.External {
+			result.WriteString(fmt.Sprintf("  %q -> %q [style=dashed];\n", p.Path, e))
+		}
+	}
+
+	result.WriteString("}\n")
+	return result.String()
+}
+
+func formatGraphMermaid(graph *Graph) string {
+	var result strings.Builder
+	result.WriteString("graph LR\n")
+
+	// Mermaid node IDs can't contain slashes or dots, so number them and
+	// label them with the package path.
+	ids := make(map[string]string)
+	id := func(path string, external bool) string {
+		if n, ok := ids[path]; ok {
+			return n
+		}
+		n := fmt.Sprintf("p%d", len(ids))
+		ids[path] = n
+		if external {
+			result.WriteString(fmt.Sprintf("  %s([%q])\n", n, path))
+		} else {
+			result.WriteString(fmt.Sprintf("  %s[%q]\n", n, path))
+		}
+		return n
+	}
+
+	for _, p := range graph.Packages {
+		id(p.Path, false)
+	}
+	for _, p := range graph.Packages {
+		for _, i := range p.Imports {
+			result.WriteString(fmt.Sprintf("  %s --> %s\n", id(p.Path, false), id(i, false)))
+		}
+		for _, e := range p.External {
+			n := id(e, true)
+			result.WriteString(fmt.Sprintf("  %s -.-> %s\n", id(p.Path, false), n))
+		}
+	}
+	return result.String()
+}
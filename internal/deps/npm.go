@@ -0,0 +1,180 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	PackageManager       string            `json:"packageManager"`
+	Engines              map[string]string `json:"engines"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Overrides            json.RawMessage   `json:"overrides"`
+	Resolutions          map[string]string `json:"resolutions"`
+	Workspaces           json.RawMessage   `json:"workspaces"`
+}
+
+type packageLock struct {
+	LockfileVersion int `json:"lockfileVersion"`
+	Packages        map[string]struct {
+		Version string `json:"version"`
+	} `json:"packages"`
+	Dependencies map[string]struct {
+		Version string `json:"version"`
+	} `json:"dependencies"`
+}
+
+func parsePackageJSON(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{Path: "package.json", Ecosystem: "npm", Name: pkg.Name}
+
+	for _, name := range sortedKeys(pkg.Engines) {
+		m.Toolchains = append(m.Toolchains, Toolchain{Name: name, Version: pkg.Engines[name]})
+	}
+	if pkg.PackageManager != "" {
+		m.Toolchains = append(m.Toolchains, Toolchain{Name: "packageManager", Version: pkg.PackageManager})
+	}
+
+	lock := readPackageLock(dir)
+	addNpmDeps(m, pkg.Dependencies, "", lock)
+	addNpmDeps(m, pkg.DevDependencies, "dev", lock)
+	addNpmDeps(m, pkg.PeerDependencies, "peer", lock)
+	addNpmDeps(m, pkg.OptionalDependencies, "optional", lock)
+
+	for name, to := range flattenOverrides("", pkg.Overrides) {
+		m.Overrides = append(m.Overrides, Override{From: name, To: to})
+	}
+	for _, name := range sortedKeys(pkg.Resolutions) {
+		m.Overrides = append(m.Overrides, Override{From: name, To: pkg.Resolutions[name]})
+	}
+	sort.Slice(m.Overrides, func(i, j int) bool {
+		return m.Overrides[i].From < m.Overrides[j].From
+	})
+
+	m.Workspace = parseWorkspaces(pkg.Workspaces)
+
+	switch {
+	case lock != nil:
+		m.Lockfile = fmt.Sprintf("package-lock.json (v%d)", lock.LockfileVersion)
+	case fileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		m.Lockfile = "pnpm-lock.yaml"
+	case fileExists(filepath.Join(dir, "yarn.lock")):
+		m.Lockfile = "yarn.lock"
+	case fileExists(filepath.Join(dir, "bun.lockb")):
+		m.Lockfile = "bun.lockb"
+	}
+
+	return m, nil
+}
+
+func readPackageLock(dir string) *packageLock {
+	data, err := os.ReadFile(filepath.Join(dir, "package-lock.json"))
+	if err != nil {
+		return nil
+	}
+	var lock packageLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+	return &lock
+}
+
+// resolved returns the locked version of a direct dependency, if known.
+func (l *packageLock) resolved(name string) string {
+	if l == nil {
+		return ""
+	}
+	if p, ok := l.Packages["node_modules/"+name]; ok {
+		return p.Version
+	}
+	if d, ok := l.Dependencies[name]; ok {
+		return d.Version
+	}
+	return ""
+}
+
+// addNpmDeps adds dependencies with their declared range and, when a lockfile
+// is present, the resolved version.
+func addNpmDeps(m *Manifest, deps map[string]string, kind string, lock *packageLock) {
+	for _, name := range sortedKeys(deps) {
+		version := deps[name]
+		if resolved := lock.resolved(name); resolved != "" && resolved != version {
+			version += " (" + resolved + ")"
+		}
+		m.Dependencies = append(m.Dependencies, Dependency{Name: name, Version: version, Kind: kind})
+	}
+}
+
+// flattenOverrides flattens npm's nested "overrides" object into
+// "parent > child" paths.
+func flattenOverrides(prefix string, raw json.RawMessage) map[string]string {
+	result := make(map[string]string)
+	if len(raw) == 0 {
+		return result
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		var value string
+		if err := json.Unmarshal(raw, &value); err == nil && prefix != "" {
+			result[prefix] = value
+		}
+		return result
+	}
+
+	for key, child := range obj {
+		name := key
+		if key == "." {
+			name = prefix
+		} else if prefix != "" {
+			name = prefix + " > " + key
+		}
+		for k, v := range flattenOverrides(name, child) {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// parseWorkspaces accepts both the array and the {"packages": [...]} forms.
+func parseWorkspaces(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &obj); err == nil {
+		return obj.Packages
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
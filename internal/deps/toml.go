@@ -0,0 +1,166 @@
+package deps
+
+import (
+	"bufio"
+	"os"
+	"strings"
+)
+
+// tomlValue is a TOML value reduced to what manifests need: a scalar, an
+// array of scalars, or an inline table of scalars.
+type tomlValue struct {
+	str   string
+	list  []string
+	table map[string]string
+}
+
+// tomlDoc maps a table name ("" for the top level) to its key/value pairs.
+//
+// This is deliberately not a full TOML parser: it understands tables, string
+// and bare scalars, (multi-line) arrays and inline tables, which covers
+// pyproject.toml and Cargo.toml dependency declarations.
+type tomlDoc map[string]map[string]tomlValue
+
+func parseTOML(path string) (tomlDoc, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	doc := tomlDoc{"": {}}
+	table := ""
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var pending string
+	for scanner.Scan() {
+		line := stripTOMLComment(scanner.Text())
+		if pending != "" {
+			line = pending + " " + line
+			pending = ""
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && !strings.Contains(line, "=") {
+			table = strings.Trim(line, "[] ")
+			if doc[table] == nil {
+				doc[table] = map[string]tomlValue{}
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		raw = strings.TrimSpace(raw)
+
+		// Keep reading until brackets and braces balance for multi-line arrays.
+		if !tomlBalanced(raw) {
+			pending = line
+			continue
+		}
+
+		doc[table][unquote(strings.TrimSpace(key))] = parseTOMLValue(raw)
+	}
+
+	return doc, scanner.Err()
+}
+
+// stripTOMLComment removes a trailing # comment that is not inside a string.
+func stripTOMLComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func tomlBalanced(s string) bool {
+	depth := 0
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+func parseTOMLValue(raw string) tomlValue {
+	switch {
+	case strings.HasPrefix(raw, "["):
+		var list []string
+		for _, item := range splitTopLevel(raw[1:len(raw)-1], ',') {
+			if item = strings.TrimSpace(item); item != "" && !strings.HasPrefix(item, "{") {
+				list = append(list, unquote(item))
+			}
+		}
+		return tomlValue{list: list}
+	case strings.HasPrefix(raw, "{"):
+		table := make(map[string]string)
+		for _, pair := range splitTopLevel(raw[1:len(raw)-1], ',') {
+			if k, v, ok := strings.Cut(pair, "="); ok {
+				table[unquote(strings.TrimSpace(k))] = unquote(strings.TrimSpace(v))
+			}
+		}
+		return tomlValue{table: table}
+	default:
+		return tomlValue{str: unquote(raw)}
+	}
+}
+
+// splitTopLevel splits s on sep, ignoring separators inside strings or nested
+// arrays and tables.
+func splitTopLevel(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '[' || r == '{':
+			depth++
+		case r == ']' || r == '}':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,361 @@
+package dir
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Layout is an expected directory structure. Patterns are root-relative
+// globs where "**" matches any number of directories and a trailing "/"
+// matches directories only; a pattern without a slash matches the base name
+// at any depth, like .gitignore.
+type Layout struct {
+	Required  []string            `json:"required,omitempty"`  // must each match at least one path
+	Forbidden []string            `json:"forbidden,omitempty"` // must not match anything
+	Allowed   []string            `json:"allowed,omitempty"`   // if set, every file must match one of these or Required
+	Placement map[string][]string `json:"placement,omitempty"` // files matching a key must be in a directory matching one of its values
+}
+
+// LoadLayout reads a layout spec: JSON, or a tree in any format printed by
+// context dir whose paths are all required.
+func LoadLayout(file string) (*Layout, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(string(data))
+	if strings.HasPrefix(text, "{") {
+		var layout Layout
+		if err := json.Unmarshal(data, &layout); err != nil {
+			return nil, fmt.Errorf("invalid layout %s: %w", file, err)
+		}
+		if layout.Required != nil || layout.Forbidden != nil || layout.Allowed != nil || layout.Placement != nil {
+			return &layout, nil
+		}
+	}
+
+	// Anything else is a tree saved from context dir.
+	paths, err := ParsePaths(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid layout %s: %w", file, err)
+	}
+	return &Layout{Required: paths}, nil
+}
+
+// Misplaced is a file found outside the directories it belongs in
+type Misplaced struct {
+	Path     string   `json:"path"`
+	Expected []string `json:"expected"`
+}
+
+// Unexpected is a path that the layout doesn't allow
+type Unexpected struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// CheckResult lists the differences between a tree and a Layout
+type CheckResult struct {
+	Missing    []string     `json:"missing,omitempty"`
+	Misplaced  []Misplaced  `json:"misplaced,omitempty"`
+	Unexpected []Unexpected `json:"unexpected,omitempty"`
+	// Incomplete says why reading the tree for the check stopped early, in
+	// which case required paths aren't checked: they may just be unread.
+	Incomplete string `json:"incomplete,omitempty"`
+}
+
+// Problems returns the number of differences found
+func (r *CheckResult) Problems() int {
+	return len(r.Missing) + len(r.Misplaced) + len(r.Unexpected)
+}
+
+// checkLayout compares the paths below entries with the layout. Entries
+// should include hidden and excluded paths: those are checked against the
+// patterns that name them, but not reported as merely not allowed. If
+// reading them stopped early, stopped says why, and nothing is reported
+// missing.
+func (g *Generator) checkLayout(entries []Entry, layout *Layout, stopped string) *CheckResult {
+	type item struct {
+		rel   string
+		isDir bool
+		shown bool // not hidden or excluded by the display options
+	}
+	var items []item
+	var collect func([]Entry, bool)
+	collect = func(entries []Entry, shown bool) {
+		for _, e := range entries {
+			visible := shown && g.Shows(e.Name)
+			items = append(items, item{g.relPath(e.Path), e.IsDir, visible})
+			collect(e.Children, visible)
+		}
+	}
+	collect(entries, true)
+
+	result := &CheckResult{Incomplete: stopped}
+	misplaced := make(map[string]bool)
+
+	required := layout.Required
+	if stopped != "" {
+		// A required path may be among those not read.
+		required = nil
+	}
+	for _, pattern := range required {
+		found := false
+		for _, it := range items {
+			if matchLayout(pattern, it.rel, it.isDir) {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+
+		// A required file that exists elsewhere is misplaced, not missing.
+		literal := !strings.ContainsAny(pattern, "*?[")
+		base := path.Base(strings.TrimSuffix(pattern, "/"))
+		isDir := strings.HasSuffix(pattern, "/")
+		located := false
+		if literal && strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+			for _, it := range items {
+				if it.isDir == isDir && path.Base(it.rel) == base && !misplaced[it.rel] {
+					result.Misplaced = append(result.Misplaced, Misplaced{Path: withSlash(it.rel, isDir), Expected: []string{pattern}})
+					misplaced[it.rel] = true
+					located = true
+					break
+				}
+			}
+		}
+		if !located {
+			result.Missing = append(result.Missing, pattern)
+		}
+	}
+
+	placements := make([]string, 0, len(layout.Placement))
+	for pattern := range layout.Placement {
+		placements = append(placements, pattern)
+	}
+	sort.Strings(placements)
+
+	var forbiddenDirs []string
+	for _, it := range items {
+		if underAny(it.rel, forbiddenDirs) {
+			continue
+		}
+
+		if pattern, ok := firstMatch(layout.Forbidden, it.rel, it.isDir); ok {
+			result.Unexpected = append(result.Unexpected, Unexpected{Path: withSlash(it.rel, it.isDir), Reason: "forbidden by " + pattern})
+			if it.isDir {
+				forbiddenDirs = append(forbiddenDirs, it.rel)
+			}
+			continue
+		}
+		if it.isDir {
+			continue
+		}
+
+		if len(layout.Allowed) > 0 && it.shown {
+			_, allowed := firstMatch(layout.Allowed, it.rel, false)
+			_, required := firstMatch(layout.Required, it.rel, false)
+			if !allowed && !required && !underRequiredDir(it.rel, layout.Required) {
+				result.Unexpected = append(result.Unexpected, Unexpected{Path: it.rel, Reason: "not allowed"})
+				continue
+			}
+		}
+
+		if misplaced[it.rel] {
+			continue
+		}
+		for _, pattern := range placements {
+			if !matchLayout(pattern, it.rel, false) {
+				continue
+			}
+			dir := path.Dir(it.rel)
+			if _, ok := firstMatch(layout.Placement[pattern], dir, true); !ok && !anyIs(layout.Placement[pattern], dir) {
+				result.Misplaced = append(result.Misplaced, Misplaced{Path: it.rel, Expected: layout.Placement[pattern]})
+				misplaced[it.rel] = true
+			}
+			break
+		}
+	}
+
+	return result
+}
+
+// markProblems sets Problem on the entries listed in a check result.
+func (g *Generator) markProblems(entries []Entry, r *CheckResult) {
+	problems := make(map[string]string)
+	for _, m := range r.Misplaced {
+		problems[strings.TrimSuffix(m.Path, "/")] = "misplaced"
+	}
+	for _, u := range r.Unexpected {
+		problems[strings.TrimSuffix(u.Path, "/")] = "unexpected"
+	}
+
+	var mark func([]Entry)
+	mark = func(entries []Entry) {
+		for i := range entries {
+			entries[i].Problem = problems[g.relPath(entries[i].Path)]
+			mark(entries[i].Children)
+		}
+	}
+	mark(entries)
+}
+
+// underRequiredDir reports whether rel is below a literal required directory,
+// whose contents are allowed implicitly.
+func underRequiredDir(rel string, required []string) bool {
+	for _, r := range required {
+		if strings.HasSuffix(r, "/") && !strings.ContainsAny(r, "*?[") && strings.HasPrefix(rel, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func underAny(rel string, dirs []string) bool {
+	for _, d := range dirs {
+		if strings.HasPrefix(rel, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func anyIs(values []string, s string) bool {
+	for _, v := range values {
+		if strings.TrimSuffix(v, "/") == s {
+			return true
+		}
+	}
+	return false
+}
+
+func firstMatch(patterns []string, rel string, isDir bool) (string, bool) {
+	for _, p := range patterns {
+		if matchLayout(p, rel, isDir) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func withSlash(rel string, isDir bool) string {
+	if isDir {
+		return rel + "/"
+	}
+	return rel
+}
+
+// matchLayout matches a layout pattern against a root-relative path.
+func matchLayout(pattern, rel string, isDir bool) bool {
+	if strings.HasSuffix(pattern, "/") {
+		if !isDir {
+			return false
+		}
+		pattern = strings.TrimSuffix(pattern, "/")
+	}
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		matched, _ := path.Match(pattern, path.Base(rel))
+		return matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], parts[0]); !matched {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+func (g *Generator) formatCheck(tree *Tree) string {
+	if tree.Check == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	r := tree.Check
+	name := filepath.Base(g.opts.Layout)
+	unchecked := ""
+	if r.Incomplete != "" {
+		unchecked = " [" + r.Incomplete + ", required paths not checked]"
+	}
+	if r.Problems() == 0 {
+		result.WriteString("\nLayout check (" + name + "): OK" + unchecked + "\n")
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("\nLayout check (%s): %d problem(s)%s\n", name, r.Problems(), unchecked))
+	if len(r.Missing) > 0 {
+		result.WriteString("\nMissing:\n")
+		for _, m := range r.Missing {
+			result.WriteString("  " + m + "\n")
+		}
+	}
+	if len(r.Misplaced) > 0 {
+		result.WriteString("\nMisplaced:\n")
+		for _, m := range r.Misplaced {
+			result.WriteString("  " + m.Path + " (expected " + strings.Join(m.Expected, ", ") + ")\n")
+		}
+	}
+	if len(r.Unexpected) > 0 {
+		result.WriteString("\nUnexpected:\n")
+		for _, u := range r.Unexpected {
+			result.WriteString("  " + u.Path + " (" + u.Reason + ")\n")
+		}
+	}
+	return result.String()
+}
+
+func (g *Generator) formatCheckMarkdown(tree *Tree) string {
+	if tree.Check == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	r := tree.Check
+	result.WriteString("\n## Layout Check: " + filepath.Base(g.opts.Layout) + "\n\n")
+	if r.Incomplete != "" {
+		result.WriteString("> **Incomplete:** " + r.Incomplete + ", required paths were not checked.\n\n")
+	}
+	if r.Problems() == 0 {
+		result.WriteString("No problems found.\n")
+		return result.String()
+	}
+
+	result.WriteString("| Problem | Path | Details |\n")
+	result.WriteString("|---|---|---|\n")
+	for _, m := range r.Missing {
+		result.WriteString("| missing | `" + m + "` | |\n")
+	}
+	for _, m := range r.Misplaced {
+		result.WriteString("| misplaced | `" + m.Path + "` | expected " + strings.Join(m.Expected, ", ") + " |\n")
+	}
+	for _, u := range r.Unexpected {
+		result.WriteString("| unexpected | `" + u.Path + "` | " + u.Reason + " |\n")
+	}
+	return result.String()
+}
@@ -0,0 +1,96 @@
+package dir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveFocus turns the focus paths into root-relative slash paths. Paths
+// are looked up relative to the root first and the working directory second.
+func resolveFocus(root string, paths []string) ([]string, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []string
+	for _, p := range paths {
+		candidate := p
+		if !filepath.IsAbs(p) {
+			if _, err := os.Lstat(filepath.Join(root, p)); err == nil {
+				candidate = filepath.Join(root, p)
+			}
+		}
+		if _, err := os.Lstat(candidate); err != nil {
+			return nil, fmt.Errorf("cannot access focus path %s: %w", p, err)
+		}
+		abs, err := filepath.Abs(candidate)
+		if err != nil {
+			return nil, err
+		}
+		rel := relTo(absRoot, abs)
+		if rel == ".." || strings.HasPrefix(rel, "../") {
+			return nil, fmt.Errorf("focus path %s is outside %s", p, root)
+		}
+		targets = append(targets, rel)
+	}
+	return targets, nil
+}
+
+// focusDepth is how deep directories unrelated to the focus paths are shown
+func (g *Generator) focusDepth() int {
+	if g.opts.MaxDepth > 0 {
+		return g.opts.MaxDepth
+	}
+	return 1
+}
+
+// pruneFocus keeps the focused paths fully expanded together with their
+// ancestors and everything next to them, and collapses other directories
+// deeper than focusDepth, recording how many items they hide.
+func (g *Generator) pruneFocus(entries []Entry, targets []string, depth int) {
+	for i := range entries {
+		e := &entries[i]
+		if !e.IsDir {
+			continue
+		}
+
+		rel := g.relPath(e.Path)
+		switch {
+		case insideAny(rel, targets):
+		case ancestorOfAny(rel, targets) || depth < g.focusDepth():
+			g.pruneFocus(e.Children, targets, depth+1)
+		default:
+			e.Omitted = countEntries(e.Children)
+			e.Children = nil
+		}
+	}
+}
+
+func insideAny(rel string, targets []string) bool {
+	for _, t := range targets {
+		if t == "." || rel == t || strings.HasPrefix(rel, t+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func ancestorOfAny(rel string, targets []string) bool {
+	for _, t := range targets {
+		if strings.HasPrefix(t, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func countEntries(entries []Entry) int {
+	n := len(entries)
+	for _, e := range entries {
+		n += countEntries(e.Children)
+	}
+	return n
+}
@@ -0,0 +1,116 @@
+package dir
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// generatedDirs are directories of vendored third-party code.
+var generatedDirs = map[string]bool{
+	"vendor": true,
+}
+
+// generatedNames are lockfiles and other files written by tools.
+var generatedNames = map[string]bool{
+	"go.sum":              true,
+	"go.work.sum":         true,
+	"package-lock.json":   true,
+	"npm-shrinkwrap.json": true,
+	"yarn.lock":           true,
+	"pnpm-lock.yaml":      true,
+	"bun.lockb":           true,
+	"Cargo.lock":          true,
+	"poetry.lock":         true,
+	"uv.lock":             true,
+	"Pipfile.lock":        true,
+	"Gemfile.lock":        true,
+	"composer.lock":       true,
+	"flake.lock":          true,
+	"mix.lock":            true,
+	"pubspec.lock":        true,
+}
+
+// generatedGlobs are name patterns of code generator and minifier output.
+var generatedGlobs = []string{
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"*.pb.cc",
+	"*.pb.h",
+	"*.min.js",
+	"*.min.css",
+	"*.min.mjs",
+	"*.map",
+}
+
+// generatedHeader matches Go's "Code generated ... DO NOT EDIT." convention
+// (https://go.dev/s/generatedcode) and the @generated marker used by other
+// ecosystems.
+var generatedHeader = regexp.MustCompile(`(?m)^\s*(//|#|/\*|\*|--)\s*(Code generated .* DO NOT EDIT\.|@generated\b)`)
+
+// generatedSniffBytes is how much of a file is read to look for a header or
+// minified content.
+const generatedSniffBytes = 4096
+
+func checkGeneratedMode(mode string) error {
+	switch mode {
+	case "", "show", "mark", "skip":
+		return nil
+	default:
+		return fmt.Errorf("invalid generated mode %q (want mark, skip or show)", mode)
+	}
+}
+
+// isGenerated reports whether an entry is vendored, a lockfile, or was
+// written by a code generator or minifier.
+func isGenerated(e Entry) bool {
+	if e.IsDir {
+		return generatedDirs[e.Name]
+	}
+	if generatedNames[e.Name] {
+		return true
+	}
+	for _, pattern := range generatedGlobs {
+		if matched, _ := filepath.Match(pattern, e.Name); matched {
+			return true
+		}
+	}
+	if !readable(e.Mode) || e.Size == 0 {
+		return false
+	}
+	return hasGeneratedContent(e.Path)
+}
+
+// hasGeneratedContent looks for a generated-code header near the top of a
+// file, or for JavaScript and CSS that has been minified onto long lines.
+func hasGeneratedContent(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	buf := make([]byte, generatedSniffBytes)
+	n, _ := file.Read(buf)
+	buf = buf[:n]
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return false
+	}
+
+	if generatedHeader.Match(buf) {
+		return true
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".js", ".mjs", ".cjs", ".css":
+		// Hand-written code rarely averages more than a couple of hundred
+		// characters per line.
+		return n == generatedSniffBytes && bytes.Count(buf, []byte("\n")) < n/500
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package dir
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// gitStatus returns the porcelain status code (e.g. " M", "??") of each
+// changed file below root, keyed by its slash-separated path relative to
+// root. Git reports paths within the real work tree, so keys are relative to
+// match a root reached through a symlink. It returns nil if root is not in a
+// work tree or git is unavailable.
+func gitStatus(ctx context.Context, root string) map[string]string {
+	prefix, err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--show-prefix").Output()
+	if err != nil {
+		return nil
+	}
+	rootPrefix := strings.TrimSpace(string(prefix))
+
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil
+	}
+
+	status := make(map[string]string)
+	records := strings.Split(string(out), "\x00")
+	for i := 0; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+		code, path := record[:2], record[3:]
+		if rel, ok := strings.CutPrefix(path, rootPrefix); ok {
+			status[rel] = code
+		}
+
+		// Renames and copies are followed by the original path.
+		if code[0] == 'R' || code[0] == 'C' {
+			i++
+		}
+	}
+
+	return status
+}
@@ -0,0 +1,55 @@
+package dir
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitStatusSymlinkedRoot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	repo := t.TempDir()
+	writeFiles(t, repo, "src/tracked.go", "src/new.go")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "src/tracked.go"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(repo, "src", "tracked.go"), []byte("changed\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(repo, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	for _, root := range []string{link, filepath.Join(link, "src")} {
+		tree, err := NewGenerator(Options{GitStatus: true}).Walk(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make(map[string]string)
+		var collect func([]Entry)
+		collect = func(entries []Entry) {
+			for _, e := range entries {
+				if !e.IsDir {
+					got[e.Name] = e.GitStatus
+				}
+				collect(e.Children)
+			}
+		}
+		collect(tree.Entries)
+		if got["tracked.go"] != " M" || got["new.go"] != "??" {
+			t.Errorf("%s: statuses = %q, want tracked.go \" M\" and new.go \"??\"", root, got)
+		}
+	}
+}
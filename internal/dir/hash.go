@@ -0,0 +1,95 @@
+package dir
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/jupiterozeye/context/internal/xxhash"
+)
+
+// hashAlgorithms are the digests supported by Options.Hash
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha256": sha256.New,
+	"xxhash": func() hash.Hash { return xxhash.New() },
+}
+
+func checkHashAlgorithm(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := hashAlgorithms[name]; !ok {
+		return fmt.Errorf("invalid hash algorithm %q (want sha256 or xxhash)", name)
+	}
+	return nil
+}
+
+// computeHashes fills in Hash for entries and returns the digest of the
+// directory containing them.
+//
+// Files hash their contents and symlinks their target, without following it.
+// A directory's digest covers the type, digest and name of each child, like a
+// Merkle tree, so it changes whenever anything below it changes. Secret files
+// are only hashed with Options.AllowSecrets; otherwise they count towards
+// their directory by name alone.
+func (g *Generator) computeHashes(ctx context.Context, entries []Entry) string {
+	for i := range entries {
+		e := &entries[i]
+		switch {
+		case e.IsDir:
+			// Directories cut off by MaxDepth already carry their digest.
+			if e.Hash == "" || len(e.Children) > 0 {
+				e.Hash = g.computeHashes(ctx, e.Children)
+			}
+		case e.Secret && !g.opts.AllowSecrets:
+		case e.Mode&os.ModeSymlink != 0:
+			if target, err := os.Readlink(e.Path); err == nil {
+				h := hashAlgorithms[g.opts.Hash]()
+				io.WriteString(h, target)
+				e.Hash = hex.EncodeToString(h.Sum(nil))
+			}
+		case !readable(e.Mode):
+		default:
+			e.Hash, _ = interruptible(ctx, func() string { return g.hashFile(e.Path) })
+		}
+	}
+
+	sorted := make([]*Entry, len(entries))
+	for i := range entries {
+		sorted[i] = &entries[i]
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	h := hashAlgorithms[g.opts.Hash]()
+	for _, e := range sorted {
+		kind := "file"
+		switch {
+		case e.IsDir:
+			kind = "dir"
+		case e.Mode&os.ModeSymlink != 0:
+			kind = "link"
+		}
+		fmt.Fprintf(h, "%s %s %s\n", kind, e.Hash, e.Name)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashFile returns the digest of a file's contents, or "" if it can't be read.
+func (g *Generator) hashFile(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	h := hashAlgorithms[g.opts.Hash]()
+	if _, err := io.Copy(h, file); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,36 @@
+package dir
+
+import (
+	"context"
+	"os"
+)
+
+// readable reports whether a file with mode can be read for its contents.
+// Only regular files are: reading a FIFO waits for a writer and a device
+// may never end, and neither has contents to count, hash or show.
+func readable(mode os.FileMode) bool {
+	return mode.IsRegular()
+}
+
+// interruptible runs read in its own goroutine and returns its result, or
+// ok == false as soon as ctx is done. Reading a FIFO, a device or a file on
+// a hung network mount can block indefinitely and can't be cancelled, so such
+// a read is abandoned rather than waited for; it ends with the process.
+func interruptible[T any](ctx context.Context, read func() T) (result T, ok bool) {
+	if ctx.Err() != nil {
+		return result, false
+	}
+	if ctx.Done() == nil {
+		// Never cancelled, so there's nothing to wait for but the read.
+		return read(), true
+	}
+
+	done := make(chan T, 1)
+	go func() { done <- read() }()
+	select {
+	case result = <-done:
+		return result, true
+	case <-ctx.Done():
+		return result, false
+	}
+}
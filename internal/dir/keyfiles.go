@@ -0,0 +1,275 @@
+package dir
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxKeyFileBytes caps how much of a single key file is included.
+	maxKeyFileBytes = 16 * 1024
+	// maxKeyFilesTotal caps the combined size of all included key files.
+	maxKeyFilesTotal = 64 * 1024
+	// maxFilesTotal caps the combined size of explicitly selected files.
+	maxFilesTotal = 256 * 1024
+)
+
+// keyFileNames are manifest and build files looked up directly in the root.
+var keyFileNames = []string{
+	"go.mod",
+	"go.work",
+	"Makefile",
+	"GNUmakefile",
+	"justfile",
+	"Taskfile.yml",
+	"flake.nix",
+	"default.nix",
+	"package.json",
+	"tsconfig.json",
+	"Cargo.toml",
+	"pyproject.toml",
+	"requirements.txt",
+	"setup.py",
+	"Gemfile",
+	"pom.xml",
+	"build.gradle",
+	"build.gradle.kts",
+	"CMakeLists.txt",
+	"Dockerfile",
+	"Containerfile",
+	"docker-compose.yml",
+	"docker-compose.yaml",
+	"compose.yml",
+	"compose.yaml",
+	".gitlab-ci.yml",
+	".travis.yml",
+	"azure-pipelines.yml",
+	"Jenkinsfile",
+}
+
+// keyFileGlobs are CI configs and other key files that live in subdirectories
+// or have variable names, relative to the root.
+var keyFileGlobs = []string{
+	"Dockerfile.*",
+	".github/workflows/*.yml",
+	".github/workflows/*.yaml",
+	".circleci/config.yml",
+}
+
+// keyFileDocs are read last so large READMEs don't crowd out the manifests
+// within the total size budget.
+var keyFileDocs = []string{
+	"README.md",
+	"README",
+}
+
+// KeyFile is the (possibly truncated) content of a manifest or build file
+type KeyFile struct {
+	Path      string // relative to the root
+	Content   string
+	Truncated bool
+}
+
+// findKeyFiles returns the relative paths of the key files present in root:
+// manifests first, then globbed CI configs, then documentation.
+func (g *Generator) findKeyFiles(root string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+
+	add := func(rel string) {
+		if seen[rel] || g.isExcluded(filepath.Base(rel)) {
+			return
+		}
+		info, err := os.Stat(filepath.Join(root, rel))
+		if err != nil || !readable(info.Mode()) {
+			return
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+	}
+
+	for _, name := range keyFileNames {
+		add(name)
+	}
+
+	for _, pattern := range keyFileGlobs {
+		matches, _ := filepath.Glob(filepath.Join(root, pattern))
+		sort.Strings(matches)
+		for _, match := range matches {
+			rel, err := filepath.Rel(root, match)
+			if err == nil {
+				add(filepath.ToSlash(rel))
+			}
+		}
+	}
+
+	for _, name := range keyFileDocs {
+		add(name)
+	}
+
+	return paths
+}
+
+// ReadFiles reads the given root-relative files for inclusion after the tree,
+// like the key files but with a larger total budget.
+func (g *Generator) ReadFiles(root string, rels []string) []KeyFile {
+	return g.readContents(context.Background(), root, rels, maxFilesTotal)
+}
+
+func (g *Generator) readKeyFiles(ctx context.Context, root string) []KeyFile {
+	return g.readContents(ctx, root, g.findKeyFiles(root), maxKeyFilesTotal)
+}
+
+// readContents reads files relative to root, applying the per-file cap and
+// the total budget. Files that no longer fit in the budget are skipped, and
+// likely secret files are never read unless AllowSecrets is set.
+func (g *Generator) readContents(ctx context.Context, root string, rels []string, budget int) []KeyFile {
+	var files []KeyFile
+	remaining := budget
+
+	for _, rel := range rels {
+		if remaining <= 0 {
+			break
+		}
+
+		path := filepath.Join(root, rel)
+		if isSecret(path) && !g.opts.AllowSecrets {
+			continue
+		}
+
+		if info, err := os.Stat(path); err != nil || !readable(info.Mode()) {
+			continue
+		}
+		data, ok := interruptible(ctx, func() []byte {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil
+			}
+			return data
+		})
+		if !ok || data == nil {
+			continue
+		}
+
+		limit := maxKeyFileBytes
+		if remaining < limit {
+			limit = remaining
+		}
+
+		kf := KeyFile{Path: rel, Content: string(data)}
+		if len(data) > limit {
+			// Cut at a rune boundary so the kept text stays valid UTF-8.
+			for limit > 0 && !utf8.RuneStart(data[limit]) {
+				limit--
+			}
+			kf.Content = string(data[:limit])
+			kf.Truncated = true
+		}
+		remaining -= len(kf.Content)
+
+		files = append(files, kf)
+	}
+
+	return files
+}
+
+func (g *Generator) formatKeyFiles(files []KeyFile) string {
+	var result strings.Builder
+
+	for _, kf := range files {
+		result.WriteString("\n==> " + kf.Path + " <==\n")
+		result.WriteString(strings.TrimRight(kf.Content, "\n"))
+		result.WriteString("\n")
+		if kf.Truncated {
+			result.WriteString("[... truncated ...]\n")
+		}
+	}
+
+	return result.String()
+}
+
+func (g *Generator) formatKeyFilesMarkdown(title string, files []KeyFile) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\n## " + title + "\n")
+
+	for _, kf := range files {
+		content := strings.TrimRight(kf.Content, "\n")
+		fence := codeFence(content)
+
+		result.WriteString("\n### " + kf.Path + "\n\n")
+		result.WriteString(fence + languageFor(kf.Path) + "\n")
+		result.WriteString(content + "\n")
+		result.WriteString(fence + "\n")
+		if kf.Truncated {
+			result.WriteString(fmt.Sprintf("\n_Truncated to %d bytes._\n", len(kf.Content)))
+		}
+	}
+
+	return result.String()
+}
+
+// codeFence returns a backtick fence longer than any backtick run in content,
+// so embedded code blocks (e.g. in a README) don't terminate it early.
+func codeFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// languageFor returns the markdown code block language hint for a file.
+func languageFor(path string) string {
+	base := filepath.Base(path)
+	switch {
+	case base == "go.mod" || base == "go.work":
+		return "go"
+	case base == "Makefile" || base == "GNUmakefile":
+		return "makefile"
+	case base == "Dockerfile" || base == "Containerfile" || strings.HasPrefix(base, "Dockerfile."):
+		return "dockerfile"
+	case base == "Jenkinsfile" || strings.HasSuffix(base, ".gradle"):
+		return "groovy"
+	case base == "CMakeLists.txt":
+		return "cmake"
+	}
+
+	switch filepath.Ext(base) {
+	case ".nix":
+		return "nix"
+	case ".json":
+		return "json"
+	case ".toml":
+		return "toml"
+	case ".yml", ".yaml":
+		return "yaml"
+	case ".md":
+		return "markdown"
+	case ".py":
+		return "python"
+	case ".xml":
+		return "xml"
+	case ".kts":
+		return "kotlin"
+	}
+	return ""
+}
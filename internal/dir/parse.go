@@ -0,0 +1,183 @@
+package dir
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// treeDrawing matches the drawing before a name in any charset: indentation
+// units such as "│   ", "|   " or plain spaces, then at most one connector
+// such as "├── " or "`-- ". Names may themselves start with "-" or "`".
+var treeDrawing = regexp.MustCompile("^(?:[ \t]|[│|](?:[ \t]|$))*(?:[├└╰]─+ ?|[|`]-+ )?")
+
+// treeAnnotation matches the markers appended to entries by formatTree
+var treeAnnotation = regexp.MustCompile(`( \[(secret|secret, omitted|generated|unexpected|misplaced)\]| \(\d+ items?\))+$`)
+
+// treeComment matches explanations written after entries in hand-written or
+// suggested trees, such as "main.go  # entry point" or "api/ <- handlers".
+var treeComment = regexp.MustCompile(`\s+(#|//|<-|←|--\s).*$`)
+
+// ParsePaths reads a tree in the tree, markdown or JSON format and returns the
+// root-relative paths it lists, directories ending in "/".
+func ParsePaths(text string) ([]string, error) {
+	if strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return ParseJSONTree([]byte(text))
+	}
+	return ParseTree(text)
+}
+
+// ParseJSONTree reads a tree in the JSON format and returns the paths below
+// its root, directories ending in "/".
+func ParseJSONTree(data []byte) ([]string, error) {
+	var root jsonEntry
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON tree: %w", err)
+	}
+
+	var paths []string
+	var walk func(prefix string, entries []jsonEntry) error
+	walk = func(prefix string, entries []jsonEntry) error {
+		for _, e := range entries {
+			if e.Name == "" || strings.HasPrefix(e.Name, "/") || strings.Contains("/"+e.Name+"/", "/../") {
+				return fmt.Errorf("invalid name %q in tree", e.Name)
+			}
+			path := prefix + strings.TrimSuffix(e.Name, "/")
+			if e.Type == "directory" || len(e.Children) > 0 {
+				paths = append(paths, path+"/")
+				if err := walk(path+"/", e.Children); err != nil {
+					return err
+				}
+				continue
+			}
+			paths = append(paths, path)
+		}
+		return nil
+	}
+	if err := walk("", root.Children); err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no entries found in JSON tree")
+	}
+	return paths, nil
+}
+
+// ParseTree reads a tree as printed by the tree or markdown formats, in any
+// charset, and returns the root-relative paths it lists. Directories end in
+// "/". A root line such as "project/" is recognised and left out.
+func ParseTree(text string) ([]string, error) {
+	text = fencedBlock(text)
+
+	var lines []treeLine
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimRight(raw, " \r")
+		drawing := treeDrawing.FindString(raw)
+		name := raw[len(drawing):]
+		if name == "" {
+			continue
+		}
+		name = treeComment.ReplaceAllString(name, "")
+		name = treeAnnotation.ReplaceAllString(name, "")
+		lines = append(lines, treeLine{utf8.RuneCountInString(drawing), name})
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("no tree found")
+	}
+
+	// The first line is the root when it's a directory and everything else
+	// is drawn below it.
+	if len(lines) > 1 && lines[0].column == 0 && strings.HasSuffix(lines[0].name, "/") {
+		root := true
+		for _, l := range lines[1:] {
+			if l.column == 0 {
+				root = false
+				break
+			}
+		}
+		if root {
+			lines = lines[1:]
+		}
+	}
+
+	// With --full-paths every nested entry repeats its parent's path.
+	if paths, ok := nestPaths(lines, true); ok {
+		return checkPaths(paths)
+	}
+	paths, _ := nestPaths(lines, false)
+	return checkPaths(paths)
+}
+
+// treeLine is an entry of a parsed tree with the column its name starts at
+type treeLine struct {
+	column int
+	name   string
+}
+
+// nestPaths turns indented entries into paths. With full set, names must
+// already include their parent directory, otherwise it reports false.
+func nestPaths(lines []treeLine, full bool) ([]string, bool) {
+	type parent struct {
+		column int
+		path   string
+	}
+	var stack []parent
+	var paths []string
+	seen := make(map[string]bool)
+
+	for _, l := range lines {
+		for len(stack) > 0 && stack[len(stack)-1].column >= l.column {
+			stack = stack[:len(stack)-1]
+		}
+
+		path := l.name
+		if len(stack) > 0 {
+			dir := stack[len(stack)-1].path
+			switch {
+			case !full:
+				path = dir + path
+			case !strings.HasPrefix(path, dir) || path == dir:
+				return nil, false
+			}
+		}
+
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+		if strings.HasSuffix(path, "/") {
+			stack = append(stack, parent{l.column, path})
+		}
+	}
+
+	return paths, true
+}
+
+// checkPaths rejects paths that would leave the root.
+func checkPaths(paths []string) ([]string, error) {
+	for _, path := range paths {
+		if strings.HasPrefix(path, "/") || strings.Contains("/"+path+"/", "/../") {
+			return nil, fmt.Errorf("invalid path %q in tree", path)
+		}
+	}
+	return paths, nil
+}
+
+// fencedBlock returns the contents of the first ``` block in markdown, or
+// text itself if there is none.
+func fencedBlock(text string) string {
+	start := strings.Index(text, "```")
+	if start < 0 {
+		return text
+	}
+	rest := text[start+3:]
+	if i := strings.Index(rest, "\n"); i >= 0 {
+		rest = rest[i+1:]
+	}
+	if end := strings.Index(rest, "```"); end >= 0 {
+		rest = rest[:end]
+	}
+	return rest
+}
@@ -0,0 +1,89 @@
+package dir
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestParseTreeRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "-foo.txt", "--help.md", "`x", "src/-d/`y", "src/main.go", "a b.txt")
+	want := []string{"--help.md", "-foo.txt", "`x", "a b.txt", "src/", "src/-d/", "src/-d/`y", "src/main.go"}
+
+	for _, format := range []string{"tree", "markdown", "json"} {
+		for _, charset := range []string{"unicode", "ascii", "rounded", "indent"} {
+			for _, full := range []bool{false, true} {
+				g := NewGenerator(Options{Format: format, Charset: charset, FullPaths: full})
+				text, err := g.Generate(root)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, err := ParsePaths(text)
+				if err != nil {
+					t.Fatalf("%s/%s/full=%v: %v", format, charset, full, err)
+				}
+				sort.Strings(got)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s/%s/full=%v: ParsePaths =\n%q\nwant\n%q\nfrom\n%s", format, charset, full, got, want, text)
+				}
+			}
+		}
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "hand-written with comments",
+			text: "project/\n├── cmd/           # binaries\n│   └── main.go\n└── README.md <- docs\n",
+			want: []string{"cmd/", "cmd/main.go", "README.md"},
+		},
+		{
+			name: "names starting with drawing characters",
+			text: "├── -v.txt\n├── --\n├── `quoted`\n└── |pipe\n",
+			want: []string{"-v.txt", "--", "`quoted`", "|pipe"},
+		},
+		{
+			name: "ascii names starting with dashes",
+			text: "|-- --flags/\n|   `-- -x\n`-- ``\n",
+			want: []string{"--flags/", "--flags/-x", "``"},
+		},
+		{
+			name: "blank drawing lines",
+			text: "a/\n│\n├── b\n│\n└── c\n",
+			want: []string{"b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTree(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTree = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTreeErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"├── ../escape\n",
+		"└── /etc/passwd\n",
+		"dir/\n└── ../../x\n",
+	} {
+		if paths, err := ParseTree(text); err == nil {
+			t.Errorf("ParseTree(%q) = %q, want an error", text, paths)
+		}
+	}
+	if _, err := ParseJSONTree([]byte(`{"name": "x", "children": [{"name": "../y"}]}`)); err == nil {
+		t.Error("ParseJSONTree with ../y: want an error")
+	}
+}
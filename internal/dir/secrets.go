@@ -0,0 +1,105 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// secretNames are file names that almost always hold credentials.
+var secretNames = map[string]bool{
+	"credentials.json":   true,
+	"client_secret.json": true,
+	"kubeconfig":         true,
+	".netrc":             true,
+	"_netrc":             true,
+	".pgpass":            true,
+	".pypirc":            true,
+	".git-credentials":   true,
+	".htpasswd":          true,
+}
+
+// secretGlobs are name patterns for key material and environment files.
+var secretGlobs = []string{
+	".env",
+	".env.*",
+	"*.env",
+	"id_rsa*",
+	"id_dsa*",
+	"id_ecdsa*",
+	"id_ed25519*",
+	"*.pem",
+	"*.key",
+	"*.p12",
+	"*.pfx",
+	"*.jks",
+	"*.keystore",
+	"*.kubeconfig",
+}
+
+// secretSafeSuffixes mark files that match a secret pattern but are meant to
+// be shared, such as public keys and env templates.
+var secretSafeSuffixes = []string{
+	".pub",
+	".example",
+	".sample",
+	".template",
+	".dist",
+}
+
+// npmrcTokenKeys are npm/yarn settings that carry registry credentials.
+var npmrcTokenKeys = []string{
+	"_auth",
+	"_password",
+	"npmAuthToken",
+}
+
+// isSecret reports whether the file at path is likely to contain secrets.
+func isSecret(path string) bool {
+	name := filepath.Base(path)
+
+	for _, suffix := range secretSafeSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+
+	if secretNames[name] {
+		return true
+	}
+
+	for _, pattern := range secretGlobs {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	parent := filepath.Base(filepath.Dir(path))
+	if name == "config" && parent == ".kube" {
+		return true
+	}
+	if name == "credentials" && (parent == ".aws" || parent == ".docker") {
+		return true
+	}
+
+	if name == ".npmrc" || name == ".yarnrc" || name == ".yarnrc.yml" {
+		return hasNpmToken(path)
+	}
+
+	return false
+}
+
+// hasNpmToken reports whether an npm/yarn config file contains auth settings.
+func hasNpmToken(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	content := string(data)
+	for _, key := range npmrcTokenKeys {
+		if strings.Contains(content, key) {
+			return true
+		}
+	}
+	return false
+}
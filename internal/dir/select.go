@@ -0,0 +1,112 @@
+package dir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Select returns a copy of entries that keeps only the files whose Path is in
+// selected, together with the directories containing them.
+func Select(entries []Entry, selected map[string]bool) []Entry {
+	var result []Entry
+	for _, e := range entries {
+		if e.IsDir {
+			children := Select(e.Children, selected)
+			if len(children) == 0 {
+				continue
+			}
+			e.Children = children
+		} else if !selected[e.Path] {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// FilePaths returns the root-relative paths of the files in the tree, in tree
+// order.
+func (t *Tree) FilePaths() []string {
+	var paths []string
+	var walk func([]Entry)
+	walk = func(entries []Entry) {
+		for _, e := range entries {
+			if e.IsDir {
+				walk(e.Children)
+				continue
+			}
+			paths = append(paths, relTo(t.Root, e.Path))
+		}
+	}
+	walk(t.Entries)
+	return paths
+}
+
+// WalkPaths builds a tree of only the given root-relative files and the
+// directories leading to them, without reading anything else below root.
+// Files that don't exist are left out.
+func (g *Generator) WalkPaths(rootPath string, rels []string) (*Tree, error) {
+	info, err := os.Stat(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access %s: %w", rootPath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", rootPath)
+	}
+
+	g.root = rootPath
+	g.secrets = nil
+
+	tree := &Tree{Root: rootPath, Name: displayName(rootPath)}
+	for _, rel := range rels {
+		path := filepath.Join(rootPath, filepath.FromSlash(rel))
+		info, err := os.Lstat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		entries := &tree.Entries
+		parts := strings.Split(filepath.ToSlash(rel), "/")
+		dirPath := rootPath
+		for _, part := range parts[:len(parts)-1] {
+			dirPath = filepath.Join(dirPath, part)
+			entries = &findOrAddDir(entries, part, dirPath).Children
+		}
+
+		e := Entry{
+			Name: info.Name(),
+			Path: path,
+			Mode: info.Mode(),
+			Size: info.Size(),
+		}
+		if isSecret(path) {
+			e.Secret = true
+			g.secrets = append(g.secrets, g.relPath(path))
+		}
+		*entries = append(*entries, e)
+	}
+
+	var sortAll func([]Entry)
+	sortAll = func(entries []Entry) {
+		sortEntries(entries)
+		for i := range entries {
+			sortAll(entries[i].Children)
+		}
+	}
+	sortAll(tree.Entries)
+
+	tree.Secrets = g.secrets
+	return tree, nil
+}
+
+func findOrAddDir(entries *[]Entry, name, path string) *Entry {
+	for i := range *entries {
+		if e := &(*entries)[i]; e.IsDir && e.Name == name {
+			return e
+		}
+	}
+	*entries = append(*entries, Entry{Name: name, Path: path, IsDir: true, Mode: os.ModeDir | 0o755})
+	return &(*entries)[len(*entries)-1]
+}
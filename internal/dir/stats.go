@@ -0,0 +1,359 @@
+package dir
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Counts are line counts for a file or a group of files
+type Counts struct {
+	Files   int `json:"files"`
+	Lines   int `json:"lines"`
+	Code    int `json:"code"`
+	Comment int `json:"comment"`
+	Blank   int `json:"blank"`
+}
+
+// Stats are the line counts of a file or directory, with a per-language
+// breakdown for directories.
+type Stats struct {
+	Counts
+	Languages map[string]*Counts `json:"languages,omitempty"`
+}
+
+func (c *Counts) add(o Counts) {
+	c.Files += o.Files
+	c.Lines += o.Lines
+	c.Code += o.Code
+	c.Comment += o.Comment
+	c.Blank += o.Blank
+}
+
+func (s *Stats) add(language string, c Counts) {
+	s.Counts.add(c)
+	if s.Languages == nil {
+		s.Languages = make(map[string]*Counts)
+	}
+	if s.Languages[language] == nil {
+		s.Languages[language] = &Counts{}
+	}
+	s.Languages[language].add(c)
+}
+
+func (s *Stats) merge(o *Stats) {
+	for language, c := range o.Languages {
+		s.add(language, *c)
+	}
+}
+
+// language describes how comments are written in a source language
+type language struct {
+	name       string
+	line       []string // line comment prefixes
+	blockStart string
+	blockEnd   string
+}
+
+var (
+	langC      = func(name string) language { return language{name, []string{"//"}, "/*", "*/"} }
+	langHash   = func(name string) language { return language{name: name, line: []string{"#"}} }
+	langMarkup = func(name string) language { return language{name: name, blockStart: "<!--", blockEnd: "-->"} }
+	langPlain  = func(name string) language { return language{name: name} }
+)
+
+var languagesByExt = map[string]language{
+	".go":     langC("Go"),
+	".c":      langC("C"),
+	".h":      langC("C"),
+	".cc":     langC("C++"),
+	".cpp":    langC("C++"),
+	".cxx":    langC("C++"),
+	".hpp":    langC("C++"),
+	".cs":     langC("C#"),
+	".java":   langC("Java"),
+	".kt":     langC("Kotlin"),
+	".kts":    langC("Kotlin"),
+	".scala":  langC("Scala"),
+	".swift":  langC("Swift"),
+	".rs":     langC("Rust"),
+	".js":     langC("JavaScript"),
+	".mjs":    langC("JavaScript"),
+	".cjs":    langC("JavaScript"),
+	".jsx":    langC("JSX"),
+	".ts":     langC("TypeScript"),
+	".mts":    langC("TypeScript"),
+	".tsx":    langC("TSX"),
+	".dart":   langC("Dart"),
+	".zig":    langC("Zig"),
+	".proto":  langC("Protocol Buffers"),
+	".css":    {"CSS", nil, "/*", "*/"},
+	".scss":   langC("SCSS"),
+	".less":   langC("Less"),
+	".php":    {"PHP", []string{"//", "#"}, "/*", "*/"},
+	".py":     langHash("Python"),
+	".rb":     langHash("Ruby"),
+	".pl":     langHash("Perl"),
+	".sh":     langHash("Shell"),
+	".bash":   langHash("Shell"),
+	".zsh":    langHash("Shell"),
+	".fish":   langHash("Fish"),
+	".ps1":    {"PowerShell", []string{"#"}, "<#", "#>"},
+	".r":      langHash("R"),
+	".ex":     langHash("Elixir"),
+	".exs":    langHash("Elixir"),
+	".nix":    {"Nix", []string{"#"}, "/*", "*/"},
+	".yml":    langHash("YAML"),
+	".yaml":   langHash("YAML"),
+	".toml":   langHash("TOML"),
+	".tf":     {"HCL", []string{"#", "//"}, "/*", "*/"},
+	".hcl":    {"HCL", []string{"#", "//"}, "/*", "*/"},
+	".lua":    {"Lua", []string{"--"}, "--[[", "]]"},
+	".sql":    {"SQL", []string{"--"}, "/*", "*/"},
+	".hs":     {"Haskell", []string{"--"}, "{-", "-}"},
+	".elm":    {"Elm", []string{"--"}, "{-", "-}"},
+	".erl":    {"Erlang", []string{"%"}, "", ""},
+	".clj":    {"Clojure", []string{";"}, "", ""},
+	".el":     {"Emacs Lisp", []string{";"}, "", ""},
+	".vim":    {"Vim script", []string{"\""}, "", ""},
+	".html":   langMarkup("HTML"),
+	".htm":    langMarkup("HTML"),
+	".xml":    langMarkup("XML"),
+	".svg":    langMarkup("SVG"),
+	".vue":    {"Vue", []string{"//"}, "<!--", "-->"},
+	".svelte": {"Svelte", []string{"//"}, "<!--", "-->"},
+	".md":     langMarkup("Markdown"),
+	".json":   langPlain("JSON"),
+	".txt":    langPlain("Text"),
+}
+
+var languagesByName = map[string]language{
+	"Makefile":       langHash("Makefile"),
+	"GNUmakefile":    langHash("Makefile"),
+	"Dockerfile":     langHash("Dockerfile"),
+	"Containerfile":  langHash("Dockerfile"),
+	"CMakeLists.txt": langHash("CMake"),
+	"Jenkinsfile":    langC("Groovy"),
+	"justfile":       langHash("Just"),
+}
+
+// detectLanguage returns the language of a file, if it is one we count.
+func detectLanguage(name string) (language, bool) {
+	if lang, ok := languagesByName[name]; ok {
+		return lang, true
+	}
+	lang, ok := languagesByExt[strings.ToLower(filepath.Ext(name))]
+	return lang, ok
+}
+
+// countLines counts the code, comment and blank lines of a file. Binary
+// files, and files that can't be read in full, such as minified code with a
+// line over the 4 MB scanner limit, are reported as not countable rather
+// than counted short.
+func countLines(path string, lang language) (Counts, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Counts{}, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	c := Counts{Files: 1}
+	inBlock := false
+
+	for scanner.Scan() {
+		raw := scanner.Bytes()
+		if bytes.IndexByte(raw, 0) >= 0 {
+			return Counts{}, false
+		}
+
+		c.Lines++
+		line := strings.TrimSpace(string(raw))
+
+		switch {
+		case line == "" && !inBlock:
+			c.Blank++
+		case inBlock:
+			c.Comment++
+			if strings.Contains(line, lang.blockEnd) {
+				inBlock = false
+			}
+		case hasAnyPrefix(line, lang.line):
+			c.Comment++
+		case lang.blockStart != "" && strings.HasPrefix(line, lang.blockStart):
+			c.Comment++
+			rest := line[len(lang.blockStart):]
+			inBlock = !strings.Contains(rest, lang.blockEnd)
+		default:
+			c.Code++
+			if lang.blockStart != "" {
+				if i := strings.LastIndex(line, lang.blockStart); i >= 0 {
+					inBlock = !strings.Contains(line[i+len(lang.blockStart):], lang.blockEnd)
+				}
+			}
+		}
+	}
+	if scanner.Err() != nil {
+		return Counts{}, false
+	}
+
+	return c, true
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// computeStats fills in Language and Stats for entries and returns the
+// combined stats of all of them.
+func computeStats(ctx context.Context, entries []Entry) *Stats {
+	total := &Stats{}
+	for i := range entries {
+		e := &entries[i]
+
+		if e.IsDir {
+			// Directories cut off by MaxDepth already carry their stats.
+			if e.Stats == nil || len(e.Children) > 0 {
+				e.Stats = computeStats(ctx, e.Children)
+			}
+			total.merge(e.Stats)
+			continue
+		}
+
+		lang, ok := detectLanguage(e.Name)
+		if !ok || e.Secret || !readable(e.Mode) {
+			continue
+		}
+		counts, ok := interruptible(ctx, func() *Counts {
+			if c, ok := countLines(e.Path, lang); ok {
+				return &c
+			}
+			return nil
+		})
+		if !ok || counts == nil {
+			continue
+		}
+
+		e.Language = lang.name
+		e.Stats = &Stats{Counts: *counts}
+		total.add(lang.name, *counts)
+	}
+	return total
+}
+
+// statsRow is a line in a statistics table
+type statsRow struct {
+	label string
+	Counts
+}
+
+func languageRows(s *Stats) []statsRow {
+	var rows []statsRow
+	for name, c := range s.Languages {
+		rows = append(rows, statsRow{name, *c})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Code != rows[j].Code {
+			return rows[i].Code > rows[j].Code
+		}
+		return rows[i].label < rows[j].label
+	})
+	return rows
+}
+
+// directoryRows breaks the totals down by top-level directory, with files
+// directly in the root grouped as "./".
+func directoryRows(tree *Tree) []statsRow {
+	var rows []statsRow
+	var rootFiles Counts
+	for _, e := range tree.Entries {
+		if e.Stats == nil || e.Stats.Files == 0 {
+			continue
+		}
+		if e.IsDir {
+			rows = append(rows, statsRow{e.Name + "/", e.Stats.Counts})
+		} else {
+			rootFiles.add(e.Stats.Counts)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Code != rows[j].Code {
+			return rows[i].Code > rows[j].Code
+		}
+		return rows[i].label < rows[j].label
+	})
+	if rootFiles.Files > 0 {
+		rows = append(rows, statsRow{"./", rootFiles})
+	}
+	return rows
+}
+
+func (g *Generator) formatStats(tree *Tree) string {
+	if tree.Stats == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\nCode statistics:\n\n")
+	writeStatsText(&result, "Language", languageRows(tree.Stats), tree.Stats.Counts)
+	result.WriteString("\n")
+	writeStatsText(&result, "Directory", directoryRows(tree), tree.Stats.Counts)
+	return result.String()
+}
+
+func writeStatsText(result *strings.Builder, heading string, rows []statsRow, total Counts) {
+	width := len(heading)
+	for _, r := range rows {
+		if len(r.label) > width {
+			width = len(r.label)
+		}
+	}
+
+	line := func(label string, c Counts) {
+		result.WriteString(fmt.Sprintf("%-*s %7d %9d %9d %9d %9d\n",
+			width, label, c.Files, c.Lines, c.Code, c.Comment, c.Blank))
+	}
+
+	result.WriteString(fmt.Sprintf("%-*s %7s %9s %9s %9s %9s\n",
+		width, heading, "Files", "Lines", "Code", "Comments", "Blanks"))
+	for _, r := range rows {
+		line(r.label, r.Counts)
+	}
+	line("Total", total)
+}
+
+func (g *Generator) formatStatsMarkdown(tree *Tree) string {
+	if tree.Stats == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\n## Code Statistics\n\n")
+	writeStatsMarkdown(&result, "Language", languageRows(tree.Stats), tree.Stats.Counts)
+	result.WriteString("\n")
+	writeStatsMarkdown(&result, "Directory", directoryRows(tree), tree.Stats.Counts)
+	return result.String()
+}
+
+func writeStatsMarkdown(result *strings.Builder, heading string, rows []statsRow, total Counts) {
+	result.WriteString("| " + heading + " | Files | Lines | Code | Comments | Blanks |\n")
+	result.WriteString("|---|--:|--:|--:|--:|--:|\n")
+	for _, r := range rows {
+		result.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %d |\n",
+			r.label, r.Files, r.Lines, r.Code, r.Comment, r.Blank))
+	}
+	result.WriteString(fmt.Sprintf("| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** |\n",
+		total.Files, total.Lines, total.Code, total.Comment, total.Blank))
+}
@@ -0,0 +1,39 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCountLines(t *testing.T) {
+	goLang, _ := detectLanguage("main.go")
+	tests := []struct {
+		name    string
+		content string
+		want    Counts
+		ok      bool
+	}{
+		{
+			name:    "code comments and blanks",
+			content: "package main\n\n// comment\n/* block\nstill */\nfunc main() {}\n",
+			want:    Counts{Files: 1, Lines: 6, Code: 2, Comment: 3, Blank: 1},
+			ok:      true,
+		},
+		{name: "binary", content: "package main\x00\n"},
+		{name: "line over the scanner limit", content: "var x = \"" + strings.Repeat("a", 5*1024*1024) + "\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "main.go")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := countLines(path, goLang)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("countLines = %+v, %v, want %+v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package dir
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TopEntry is a file or directory in a size report
+type TopEntry struct {
+	Path  string `json:"path"` // relative to the root, directories end in "/"
+	Bytes int64  `json:"bytes"`
+	Lines int    `json:"lines"`
+}
+
+// Top lists the largest files and directories of a tree
+type Top struct {
+	TotalBytes   int64      `json:"total_bytes"`
+	TotalLines   int        `json:"total_lines"`
+	FilesBySize  []TopEntry `json:"files_by_size"`
+	FilesByLines []TopEntry `json:"files_by_lines"`
+	DirsBySize   []TopEntry `json:"dirs_by_size"`
+	DirsByLines  []TopEntry `json:"dirs_by_lines"`
+}
+
+// computeTop measures every file and directory below entries and keeps the n
+// largest of each by bytes and by lines. Directories include everything
+// below them, like du.
+func (g *Generator) computeTop(ctx context.Context, entries []Entry, n int) *Top {
+	var files, dirs []TopEntry
+
+	var measure func([]Entry) (int64, int)
+	measure = func(entries []Entry) (int64, int) {
+		var size int64
+		var lines int
+		for _, e := range entries {
+			if e.IsDir {
+				s, l := measure(e.Children)
+				dirs = append(dirs, TopEntry{Path: g.relPath(e.Path) + "/", Bytes: s, Lines: l})
+				size += s
+				lines += l
+				continue
+			}
+			// Links have no size of their own.
+			if !readable(e.Mode) {
+				continue
+			}
+			l := 0
+			if !e.Secret {
+				path := e.Path
+				l, _ = interruptible(ctx, func() int { return countNewlines(path) })
+			}
+			files = append(files, TopEntry{Path: g.relPath(e.Path), Bytes: e.Size, Lines: l})
+			size += e.Size
+			lines += l
+		}
+		return size, lines
+	}
+
+	top := &Top{}
+	top.TotalBytes, top.TotalLines = measure(entries)
+	top.FilesBySize = largest(files, n, func(e TopEntry) int64 { return e.Bytes })
+	top.FilesByLines = largest(files, n, func(e TopEntry) int64 { return int64(e.Lines) })
+	top.DirsBySize = largest(dirs, n, func(e TopEntry) int64 { return e.Bytes })
+	top.DirsByLines = largest(dirs, n, func(e TopEntry) int64 { return int64(e.Lines) })
+	return top
+}
+
+// largest returns the n entries with the highest non-zero key, ties broken by
+// path.
+func largest(entries []TopEntry, n int, key func(TopEntry) int64) []TopEntry {
+	var result []TopEntry
+	for _, e := range entries {
+		if key(e) > 0 {
+			result = append(result, e)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if key(result[i]) != key(result[j]) {
+			return key(result[i]) > key(result[j])
+		}
+		return result[i].Path < result[j].Path
+	})
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// countNewlines counts the lines of a text file, returning 0 for binaries.
+func countNewlines(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	buf := make([]byte, 32*1024)
+	lines := 0
+	var last byte = '\n'
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			if bytes.IndexByte(buf[:n], 0) >= 0 {
+				return 0
+			}
+			lines += bytes.Count(buf[:n], []byte("\n"))
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines
+}
+
+func percent(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// topSection is one list of a size report with its heading
+type topSection struct {
+	title   string
+	entries []TopEntry
+	bySize  bool // measured in bytes rather than lines
+}
+
+func (t *Top) sections() []topSection {
+	return []topSection{
+		{"Largest files by size", t.FilesBySize, true},
+		{"Largest files by lines", t.FilesByLines, false},
+		{"Largest directories by size", t.DirsBySize, true},
+		{"Largest directories by lines", t.DirsByLines, false},
+	}
+}
+
+func (t *Top) measure(e TopEntry, bySize bool) (string, float64) {
+	if bySize {
+		return formatBytes(e.Bytes), percent(e.Bytes, t.TotalBytes)
+	}
+	return fmt.Sprintf("%d", e.Lines), percent(int64(e.Lines), int64(t.TotalLines))
+}
+
+func (g *Generator) formatTop(tree *Tree) string {
+	if tree.Top == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Total: %s, %d lines\n", formatBytes(tree.Top.TotalBytes), tree.Top.TotalLines))
+	for _, s := range tree.Top.sections() {
+		if len(s.entries) == 0 {
+			continue
+		}
+		result.WriteString("\n" + s.title + ":\n")
+		for _, e := range s.entries {
+			value, pct := tree.Top.measure(e, s.bySize)
+			result.WriteString(fmt.Sprintf("  %10s %6.1f%%  %s\n", value, pct, e.Path))
+		}
+	}
+	result.WriteString("\n")
+	return result.String()
+}
+
+func (g *Generator) formatTopMarkdown(tree *Tree) string {
+	if tree.Top == nil {
+		return ""
+	}
+
+	var result strings.Builder
+	result.WriteString("\n## Size Report\n\n")
+	result.WriteString(fmt.Sprintf("Total: %s, %d lines\n", formatBytes(tree.Top.TotalBytes), tree.Top.TotalLines))
+	for _, s := range tree.Top.sections() {
+		if len(s.entries) == 0 {
+			continue
+		}
+		column := "Lines"
+		if s.bySize {
+			column = "Size"
+		}
+		result.WriteString("\n### " + s.title + "\n\n")
+		result.WriteString("| Path | " + column + " | % |\n")
+		result.WriteString("|---|--:|--:|\n")
+		for _, e := range s.entries {
+			value, pct := tree.Top.measure(e, s.bySize)
+			result.WriteString(fmt.Sprintf("| `%s` | %s | %.1f%% |\n", e.Path, value, pct))
+		}
+	}
+	return result.String()
+}
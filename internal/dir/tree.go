@@ -1,12 +1,15 @@
 package dir
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"github.com/jupiterozeye/context/internal/color"
 )
 
 type Options struct {
@@ -14,11 +17,31 @@ type Options struct {
 	Exclude       string
 	IncludeHidden bool
 	Format        string
+	KeyFiles      bool
+	AllowSecrets  bool
+	Stats         bool           // count lines of code per file and directory
+	GitStatus     bool           // record git status of files while walking
+	Hash          string         // digest files and directories: "", "sha256" or "xxhash"
+	Charset       string         // tree connectors: unicode (default), ascii, indent or rounded
+	FullPaths     bool           // show root-relative paths instead of names in the tree
+	CompactDirs   bool           // render chains of single-child directories as one entry
+	Focus         []string       // paths to show in full, collapsing unrelated directories
+	Generated     string         // generated and vendored files: show (default), mark or skip
+	Top           int            // report the N largest files and directories
+	Layout        string         // layout spec file to check the tree against
+	MaxFiles      int            // stop walking after this many entries (0 = unlimited)
+	Colors        *color.Palette // colour tree and markdown output for a terminal
 }
 
 type Generator struct {
-	opts     Options
-	excludes []string
+	opts      Options
+	excludes  []string
+	root      string
+	secrets   []string
+	gitStatus map[string]string
+	files     *int    // entries read by the current walk, shared with nested walks
+	stopped   *string // why the walk stopped early, likewise shared
+	partial   *string // why a walk for stats, digests or sizes stopped early
 }
 
 func NewGenerator(opts Options) *Generator {
@@ -35,59 +58,263 @@ func NewGenerator(opts Options) *Generator {
 	}
 }
 
+// Tree is the result of walking a directory
+type Tree struct {
+	Root     string // path as passed to Walk
+	Name     string // display name of the root directory
+	Entries  []Entry
+	KeyFiles []KeyFile
+	Files    []KeyFile    // contents of explicitly selected files
+	Secrets  []string     // root-relative paths of likely secret files
+	Stats    *Stats       // totals for the whole tree when Options.Stats is set
+	Hash     string       // digest of the whole tree when Options.Hash is set
+	Top      *Top         // size report when Options.Top is set
+	Check    *CheckResult // differences from Options.Layout
+	// Incomplete says why the walk stopped early, e.g. "timed out", in
+	// which case the tree only holds what was read until then.
+	Incomplete string
+	// Partial says why reading below the display depth, for stats, digests
+	// and the size report, stopped early, in which case those are partial.
+	Partial string
+}
+
+// Entry is a file or directory in a Tree
+type Entry struct {
+	Name      string
+	Path      string
+	IsDir     bool
+	Mode      os.FileMode
+	Size      int64
+	Secret    bool
+	Generated bool   // set when Options.Generated is "mark"
+	Problem   string // "unexpected" or "misplaced" when checked against Options.Layout
+	GitStatus string // porcelain status code, e.g. " M" or "??"
+	Language  string // set for counted source files when Options.Stats is set
+	Stats     *Stats
+	Hash      string // hex digest when Options.Hash is set
+	Omitted   int    // number of items below a directory collapsed by Options.Focus
+	Children  []Entry
+}
+
+// Generate walks rootPath and formats the tree in the configured format
 func (g *Generator) Generate(rootPath string) (string, error) {
+	return g.GenerateContext(context.Background(), rootPath)
+}
+
+// GenerateContext is like Generate but stops walking when ctx is done
+func (g *Generator) GenerateContext(ctx context.Context, rootPath string) (string, error) {
+	tree, err := g.WalkContext(ctx, rootPath)
+	if err != nil {
+		return "", err
+	}
+	return g.Format(tree)
+}
+
+// Walk reads the directory tree at rootPath according to the options
+func (g *Generator) Walk(rootPath string) (*Tree, error) {
+	return g.WalkContext(context.Background(), rootPath)
+}
+
+// WalkContext reads the directory tree at rootPath according to the options.
+// When ctx is done or Options.MaxFiles is reached it returns what was read so
+// far, with Tree.Incomplete set, instead of an error.
+func (g *Generator) WalkContext(ctx context.Context, rootPath string) (*Tree, error) {
+	if err := checkHashAlgorithm(g.opts.Hash); err != nil {
+		return nil, err
+	}
+	if err := checkGeneratedMode(g.opts.Generated); err != nil {
+		return nil, err
+	}
+
 	info, err := os.Stat(rootPath)
 	if err != nil {
-		return "", fmt.Errorf("cannot access %s: %w", rootPath, err)
+		return nil, fmt.Errorf("cannot access %s: %w", rootPath, err)
 	}
 
 	if !info.IsDir() {
-		return "", fmt.Errorf("%s is not a directory", rootPath)
+		return nil, fmt.Errorf("%s is not a directory", rootPath)
 	}
 
-	rootName := filepath.Base(rootPath)
-	if rootName == "." {
-		cwd, _ := os.Getwd()
-		rootName = filepath.Base(cwd)
+	var layout *Layout
+	if g.opts.Layout != "" {
+		if layout, err = LoadLayout(g.opts.Layout); err != nil {
+			return nil, err
+		}
+	}
+
+	var focus []string
+	if len(g.opts.Focus) > 0 {
+		if focus, err = resolveFocus(rootPath, g.opts.Focus); err != nil {
+			return nil, err
+		}
+	}
+
+	g.root = rootPath
+	g.secrets = nil
+	g.files = new(int)
+	g.stopped = new(string)
+	g.partial = new(string)
+	g.gitStatus = nil
+	if g.opts.GitStatus {
+		g.gitStatus = gitStatus(ctx, rootPath)
 	}
 
-	entries, err := g.readDir(rootPath, 1)
+	entries, err := g.readDir(ctx, rootPath, 1)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	tree := &Tree{
+		Root:       rootPath,
+		Name:       displayName(rootPath),
+		Entries:    entries,
+		Secrets:    g.secrets,
+		Incomplete: *g.stopped,
+	}
+	if ctx.Err() != nil {
+		// Reading contents could block just like the walk did.
+		return tree, nil
+	}
+
+	if g.opts.KeyFiles {
+		tree.KeyFiles = g.readKeyFiles(ctx, rootPath)
+	}
+	if g.opts.Stats {
+		tree.Stats = computeStats(ctx, tree.Entries)
+	}
+	if g.opts.Hash != "" {
+		tree.Hash = g.computeHashes(ctx, tree.Entries)
+	}
+	if g.opts.Top > 0 {
+		all := tree.Entries
+		if g.opts.MaxDepth > 0 && focus == nil {
+			all = g.entriesBelow(ctx, rootPath, 1)
+		}
+		tree.Top = g.computeTop(ctx, all, g.opts.Top)
+	}
+	if layout != nil {
+		// The spec may name hidden or excluded paths, so the check sees
+		// everything whatever the display options.
+		all, stopped := g.allEntries(ctx, rootPath)
+		tree.Check = g.checkLayout(all, layout, stopped)
+		g.markProblems(tree.Entries, tree.Check)
+	}
+	if focus != nil {
+		g.pruneFocus(tree.Entries, focus, 1)
+	}
+	// Reading contents may have been cut short too.
+	g.stop(ctx)
+	tree.Incomplete = *g.stopped
+	if tree.Incomplete == "" {
+		tree.Partial = *g.partial
+	}
+
+	return tree, nil
+}
+
+// Format renders a tree in the configured format
+func (g *Generator) Format(tree *Tree) (string, error) {
+	if _, ok := charsets[g.opts.Charset]; !ok {
+		return "", fmt.Errorf("invalid charset %q (want unicode, ascii, indent or rounded)", g.opts.Charset)
+	}
+
+	entries := tree.Entries
+	if g.opts.CompactDirs {
+		entries = compactDirs(entries)
 	}
 
-	// Format based on requested format
 	switch g.opts.Format {
 	case "json":
-		return g.formatJSON(rootName, entries)
+		return g.formatJSON(tree, entries)
+	case "top":
+		// The size report on its own, in place of the tree
+		output := strings.TrimSuffix(g.formatTop(tree), "\n")
+		if tree.Incomplete != "" || tree.Partial != "" {
+			output += "\n[incomplete: " + tree.Incomplete + tree.Partial + ", the report covers part of the tree]\n"
+		}
+		return output, nil
 	case "markdown":
-		return g.formatMarkdown(rootName, entries), nil
+		output := g.formatMarkdown(tree.Name, entries)
+		if tree.Incomplete != "" {
+			output += "\n> **Incomplete:** " + tree.Incomplete + ", the tree above is partial.\n"
+		}
+		if tree.Partial != "" {
+			output += "\n> **Partial:** " + tree.Partial + " below the display depth, sizes, stats and digests cover part of the tree.\n"
+		}
+		output += g.formatTopMarkdown(tree)
+		output += g.formatCheckMarkdown(tree)
+		output += g.formatStatsMarkdown(tree)
+		output += g.formatKeyFilesMarkdown("Key Files", tree.KeyFiles)
+		output += g.formatKeyFilesMarkdown("Files", tree.Files)
+		return output, nil
 	default: // "tree" or anything else
-		output := rootName + "/\n"
+		output := g.formatTop(tree)
+		output += tree.Name + "/\n"
 		output += g.formatTree(entries, "")
+		if tree.Incomplete != "" {
+			output += "[incomplete: " + tree.Incomplete + ", showing a partial tree]\n"
+		}
+		if tree.Partial != "" {
+			output += "[partial: " + tree.Partial + " below the display depth, sizes, stats and digests cover part of the tree]\n"
+		}
+		output += g.formatCheck(tree)
+		output += g.formatStats(tree)
+		output += g.formatKeyFiles(tree.KeyFiles)
+		output += g.formatKeyFiles(tree.Files)
 		return output, nil
 	}
 }
 
-type entry struct {
-	name     string
-	path     string
-	isDir    bool
-	children []entry
+// compactDirs merges each directory whose only child is another directory
+// into that child, so src/main/java/ is shown as a single entry. The merged
+// entry keeps the outermost directory's stats and digest, which cover the
+// whole chain, and the innermost directory's path and children.
+func compactDirs(entries []Entry) []Entry {
+	result := make([]Entry, len(entries))
+	for i, e := range entries {
+		for e.IsDir && len(e.Children) == 1 && e.Children[0].IsDir {
+			child := e.Children[0]
+			e.Name += "/" + child.Name
+			e.Path = child.Path
+			e.Omitted = child.Omitted
+			e.Children = child.Children
+		}
+		e.Children = compactDirs(e.Children)
+		result[i] = e
+	}
+	return result
 }
 
-func (g *Generator) readDir(path string, depth int) ([]entry, error) {
-	if g.opts.MaxDepth > 0 && depth > g.opts.MaxDepth {
+func (g *Generator) readDir(ctx context.Context, path string, depth int) ([]Entry, error) {
+	if g.stop(ctx) {
 		return nil, nil
 	}
 
-	files, err := os.ReadDir(path)
-	if err != nil {
+	maxDepth := g.opts.MaxDepth
+	if len(g.opts.Focus) > 0 {
+		// Focus mode walks everything and prunes afterwards.
+		maxDepth = 0
+	}
+	if maxDepth > 0 && depth > maxDepth {
+		return nil, nil
+	}
+
+	// Listing a directory on a hung network mount blocks, so it must not
+	// hold up the walk once ctx is done.
+	files, ok := interruptible(ctx, func() []os.DirEntry {
+		files, _ := os.ReadDir(path)
+		return files
+	})
+	if !ok {
+		g.stop(ctx)
 		return nil, nil
 	}
 
-	var entries []entry
+	var entries []Entry
 	for _, file := range files {
+		if g.stop(ctx) {
+			break
+		}
 		name := file.Name()
 
 		if !g.opts.IncludeHidden && strings.HasPrefix(name, ".") {
@@ -98,29 +325,203 @@ func (g *Generator) readDir(path string, depth int) ([]entry, error) {
 			continue
 		}
 
-		isDir := file.IsDir()
-		e := entry{
-			name:  name,
-			path:  filepath.Join(path, name),
-			isDir: isDir,
+		*g.files++
+		if g.opts.MaxFiles > 0 && *g.files > g.opts.MaxFiles {
+			*g.stopped = fmt.Sprintf("reached the limit of %d files", g.opts.MaxFiles)
+			break
 		}
 
-		if isDir {
-			children, _ := g.readDir(e.path, depth+1)
-			e.children = children
+		e, ok := interruptible(ctx, func() Entry { return g.probe(path, file) })
+		if !ok {
+			g.stop(ctx)
+			break
+		}
+		if e.Generated && g.opts.Generated == "skip" {
+			continue
+		}
+		if g.gitStatus != nil {
+			e.GitStatus = g.gitStatus[g.relPath(e.Path)]
+		}
+
+		if e.IsDir {
+			children, _ := g.readDir(ctx, e.Path, depth+1)
+			e.Children = children
+			if maxDepth > 0 && depth >= maxDepth && (g.opts.Stats || g.opts.Hash != "") {
+				below := g.entriesBelow(ctx, e.Path, depth+1)
+				if g.opts.Stats {
+					e.Stats = computeStats(ctx, below)
+				}
+				if g.opts.Hash != "" {
+					e.Hash = g.computeHashes(ctx, below)
+				}
+			}
+		} else if e.Secret {
+			g.secrets = append(g.secrets, g.relPath(e.Path))
 		}
 
 		entries = append(entries, e)
 	}
 
+	sortEntries(entries)
+
+	return entries, nil
+}
+
+// probe builds the entry for a directory item, with the details that need
+// the file system: its mode and size, and whether it is generated or likely
+// holds secrets.
+func (g *Generator) probe(dir string, file os.DirEntry) Entry {
+	e := Entry{
+		Name:  file.Name(),
+		Path:  filepath.Join(dir, file.Name()),
+		IsDir: file.IsDir(),
+		Mode:  file.Type(),
+	}
+	if info, err := file.Info(); err == nil {
+		e.Mode = info.Mode()
+		if !e.IsDir {
+			e.Size = info.Size()
+		}
+	}
+	if g.opts.Generated == "mark" || g.opts.Generated == "skip" {
+		e.Generated = isGenerated(e)
+	}
+	if !e.IsDir {
+		e.Secret = isSecret(e.Path)
+	}
+	return e
+}
+
+// sortEntries orders directories first, then by name
+func sortEntries(entries []Entry) {
 	sort.Slice(entries, func(i, j int) bool {
-		if entries[i].isDir != entries[j].isDir {
-			return entries[i].isDir
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
 		}
-		return entries[i].name < entries[j].name
+		return entries[i].Name < entries[j].Name
 	})
+}
 
-	return entries, nil
+// auxiliary returns a copy of g for a walk besides the displayed one. It
+// counts its own files against MaxFiles, so it neither uses up the display's
+// budget nor is cut short by it.
+func (g *Generator) auxiliary() *Generator {
+	aux := *g
+	aux.files = new(int)
+	aux.stopped = new(string)
+	aux.secrets = nil
+	return &aux
+}
+
+// entriesBelow reads everything below a directory that is not expanded
+// because of MaxDepth, so that stats and digests don't depend on the display
+// depth.
+func (g *Generator) entriesBelow(ctx context.Context, path string, depth int) []Entry {
+	unlimited := g.auxiliary()
+	unlimited.opts.MaxDepth = 0
+	entries, _ := unlimited.readDir(ctx, path, depth)
+	if *unlimited.stopped != "" && *g.partial == "" {
+		*g.partial = *unlimited.stopped
+	}
+	return entries
+}
+
+// allEntries reads everything below path, including hidden, excluded and
+// generated files, regardless of the display options, but not the contents
+// of .git. It also returns why the read stopped early, if it did.
+func (g *Generator) allEntries(ctx context.Context, path string) ([]Entry, string) {
+	everything := g.auxiliary()
+	everything.opts.MaxDepth = 0
+	everything.opts.IncludeHidden = true
+	everything.opts.Generated = ""
+	everything.excludes = []string{".git"}
+	everything.gitStatus = nil
+	entries, _ := everything.readDir(ctx, path, 1)
+	return entries, *everything.stopped
+}
+
+// stop reports whether the walk should end, recording why the first time.
+func (g *Generator) stop(ctx context.Context) bool {
+	if *g.stopped != "" {
+		return true
+	}
+	switch ctx.Err() {
+	case nil:
+		return false
+	case context.DeadlineExceeded:
+		*g.stopped = "timed out"
+	default:
+		*g.stopped = "interrupted"
+	}
+	return true
+}
+
+// displayName returns the name shown for the root directory of a tree
+func displayName(rootPath string) string {
+	name := filepath.Base(rootPath)
+	if name == "." {
+		cwd, _ := os.Getwd()
+		name = filepath.Base(cwd)
+	}
+	return name
+}
+
+func (g *Generator) relPath(path string) string {
+	return relTo(g.root, path)
+}
+
+// relTo returns path relative to root with forward slashes.
+func relTo(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// secretMarker returns the annotation shown next to a secret file in the tree.
+func (g *Generator) secretMarker() string {
+	if g.opts.AllowSecrets {
+		return " [secret]"
+	}
+	return " [secret, omitted]"
+}
+
+// label returns the text shown for an entry in the tree: its name, or its
+// root-relative path with Options.FullPaths.
+func (g *Generator) label(e Entry) string {
+	if g.opts.FullPaths {
+		return g.relPath(e.Path)
+	}
+	return e.Name
+}
+
+// paint colours an entry label for terminal output when a palette is set.
+// Git status takes precedence over the LS_COLORS file type colour.
+func (g *Generator) paint(e Entry) string {
+	text := g.label(e)
+	if g.opts.Colors == nil {
+		return text
+	}
+
+	code := g.opts.Colors.File(e.Name, e.Mode)
+	switch {
+	case e.GitStatus == "":
+	case strings.Contains(e.GitStatus, "U") || e.GitStatus == "AA" || e.GitStatus == "DD":
+		code = color.Bold + ";" + color.Red
+	case e.GitStatus == "??" || e.GitStatus[0] == 'A':
+		code = color.Green
+	default:
+		code = color.Yellow
+	}
+
+	return color.Paint(code, text)
+}
+
+// Shows reports whether an entry named name is displayed rather than hidden
+// or excluded.
+func (g *Generator) Shows(name string) bool {
+	return (g.opts.IncludeHidden || !strings.HasPrefix(name, ".")) && !g.isExcluded(name)
 }
 
 func (g *Generator) isExcluded(name string) bool {
@@ -135,29 +536,61 @@ func (g *Generator) isExcluded(name string) bool {
 	return false
 }
 
-func (g *Generator) formatTree(entries []entry, prefix string) string {
+// charset is the set of connectors used to draw a tree
+type charset struct {
+	branch string // before an entry with later siblings
+	last   string // before the last entry of a directory
+	pipe   string // below an entry with later siblings
+	space  string // below the last entry of a directory
+}
+
+var charsets = map[string]charset{
+	"":        {"├── ", "└── ", "│   ", "    "},
+	"unicode": {"├── ", "└── ", "│   ", "    "},
+	"rounded": {"├── ", "╰── ", "│   ", "    "},
+	"ascii":   {"|-- ", "`-- ", "|   ", "    "},
+	"indent":  {"  ", "  ", "  ", "  "},
+}
+
+func (g *Generator) formatTree(entries []Entry, prefix string) string {
 	var result strings.Builder
+	chars := charsets[g.opts.Charset]
 
 	for i, e := range entries {
 		isLast := i == len(entries)-1
 
-		connector := "├── "
+		connector := chars.branch
 		if isLast {
-			connector = "└── "
+			connector = chars.last
 		}
 
-		result.WriteString(prefix + connector + e.name)
-		if e.isDir {
+		result.WriteString(prefix + connector + g.paint(e))
+		if e.IsDir {
 			result.WriteString("/")
 		}
+		switch {
+		case e.Omitted == 1:
+			result.WriteString(" (1 item)")
+		case e.Omitted > 1:
+			result.WriteString(fmt.Sprintf(" (%d items)", e.Omitted))
+		}
+		if e.Generated {
+			result.WriteString(" [generated]")
+		}
+		if e.Problem != "" {
+			result.WriteString(" [" + e.Problem + "]")
+		}
+		if e.Secret {
+			result.WriteString(g.secretMarker())
+		}
 		result.WriteString("\n")
 
-		if len(e.children) > 0 {
-			extension := "│   "
+		if len(e.Children) > 0 {
+			extension := chars.pipe
 			if isLast {
-				extension = "    "
+				extension = chars.space
 			}
-			result.WriteString(g.formatTree(e.children, prefix+extension))
+			result.WriteString(g.formatTree(e.Children, prefix+extension))
 		}
 	}
 
@@ -165,17 +598,52 @@ func (g *Generator) formatTree(entries []entry, prefix string) string {
 }
 
 type jsonEntry struct {
-	Name     string      `json:"name"`
-	Type     string      `json:"type"`
-	Children []jsonEntry `json:"children,omitempty"`
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Secret    bool        `json:"secret,omitempty"`
+	Generated bool        `json:"generated,omitempty"`
+	Problem   string      `json:"problem,omitempty"`
+	Language  string      `json:"language,omitempty"`
+	Stats     *Stats      `json:"stats,omitempty"`
+	Hash      string      `json:"hash,omitempty"`
+	Omitted   int         `json:"omitted,omitempty"`
+	Children  []jsonEntry `json:"children,omitempty"`
 }
 
-func (g *Generator) formatJSON(rootName string, entries []entry) (string, error) {
-	root := jsonEntry{
-		Name:     rootName,
-		Type:     "directory",
-		Children: g.entriesToJSON(entries),
+type jsonRoot struct {
+	jsonEntry
+	HashAlgorithm string        `json:"hash_algorithm,omitempty"`
+	Top           *Top          `json:"top,omitempty"`
+	Check         *CheckResult  `json:"check,omitempty"`
+	Incomplete    string        `json:"incomplete,omitempty"`
+	Partial       string        `json:"partial,omitempty"`
+	KeyFiles      []jsonKeyFile `json:"key_files,omitempty"`
+	Files         []jsonKeyFile `json:"files,omitempty"`
+}
+
+type jsonKeyFile struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func (g *Generator) formatJSON(tree *Tree, entries []Entry) (string, error) {
+	root := jsonRoot{
+		jsonEntry: jsonEntry{
+			Name:     tree.Name,
+			Type:     "directory",
+			Stats:    tree.Stats,
+			Hash:     tree.Hash,
+			Children: g.entriesToJSON(entries),
+		},
+		HashAlgorithm: g.opts.Hash,
+		Top:           tree.Top,
+		Check:         tree.Check,
+		Incomplete:    tree.Incomplete,
+		Partial:       tree.Partial,
 	}
+	root.KeyFiles = keyFilesToJSON(tree.KeyFiles)
+	root.Files = keyFilesToJSON(tree.Files)
 
 	data, err := json.MarshalIndent(root, "", "  ")
 	if err != nil {
@@ -185,21 +653,40 @@ func (g *Generator) formatJSON(rootName string, entries []entry) (string, error)
 	return string(data) + "\n", nil
 }
 
-func (g *Generator) entriesToJSON(entries []entry) []jsonEntry {
+func keyFilesToJSON(files []KeyFile) []jsonKeyFile {
+	var result []jsonKeyFile
+	for _, kf := range files {
+		result = append(result, jsonKeyFile{
+			Path:      kf.Path,
+			Content:   kf.Content,
+			Truncated: kf.Truncated,
+		})
+	}
+	return result
+}
+
+func (g *Generator) entriesToJSON(entries []Entry) []jsonEntry {
 	var result []jsonEntry
 	for _, e := range entries {
 		entryType := "file"
-		if e.isDir {
+		if e.IsDir {
 			entryType = "directory"
 		}
 
 		je := jsonEntry{
-			Name: e.name,
-			Type: entryType,
+			Name:      e.Name,
+			Type:      entryType,
+			Secret:    e.Secret,
+			Generated: e.Generated,
+			Problem:   e.Problem,
+			Language:  e.Language,
+			Stats:     e.Stats,
+			Hash:      e.Hash,
+			Omitted:   e.Omitted,
 		}
 
-		if len(e.children) > 0 {
-			je.Children = g.entriesToJSON(e.children)
+		if len(e.Children) > 0 {
+			je.Children = g.entriesToJSON(e.Children)
 		}
 
 		result = append(result, je)
@@ -207,7 +694,7 @@ func (g *Generator) entriesToJSON(entries []entry) []jsonEntry {
 	return result
 }
 
-func (g *Generator) formatMarkdown(rootName string, entries []entry) string {
+func (g *Generator) formatMarkdown(rootName string, entries []Entry) string {
 	var result strings.Builder
 	result.WriteString("# Directory Structure: " + rootName + "\n\n")
 	result.WriteString("```\n")
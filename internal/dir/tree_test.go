@@ -0,0 +1,97 @@
+package dir
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeFiles creates files, given by slash-separated paths, below root.
+func writeFiles(t *testing.T, root string, paths ...string) {
+	t.Helper()
+	for _, p := range paths {
+		path := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func writeLayout(t *testing.T, spec string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "layout.json")
+	if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMaxFilesCheckOwnBudget(t *testing.T) {
+	root := t.TempDir()
+	// 4 visible entries: cmd/, cmd/main.go, go.mod, README.md
+	writeFiles(t, root, "cmd/main.go", "go.mod", "README.md")
+	for i := 0; i < 20; i++ {
+		writeFiles(t, root, ".git/objects/"+strconv.Itoa(i))
+	}
+	layout := writeLayout(t, `{"required": ["go.mod", "cmd/", "cmd/main.go", "README.md"]}`)
+
+	g := NewGenerator(Options{MaxFiles: 10, Layout: layout})
+	tree, err := g.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Incomplete != "" {
+		t.Errorf("Incomplete = %q, want empty", tree.Incomplete)
+	}
+	if tree.Check.Problems() != 0 || tree.Check.Incomplete != "" {
+		t.Errorf("Check = %+v, want no problems", tree.Check)
+	}
+}
+
+func TestMaxFilesCheckCutShort(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "go.mod", "README.md")
+	for i := 0; i < 20; i++ {
+		writeFiles(t, root, "node_modules/pkg/"+strconv.Itoa(i))
+	}
+	layout := writeLayout(t, `{"required": ["go.mod", "README.md", "LICENSE"], "forbidden": ["*.exe"]}`)
+
+	g := NewGenerator(Options{MaxFiles: 10, Exclude: "node_modules", Layout: layout})
+	tree, err := g.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Incomplete != "" {
+		t.Errorf("Incomplete = %q, want empty", tree.Incomplete)
+	}
+	if tree.Check.Incomplete == "" {
+		t.Error("Check.Incomplete is empty, want a reason")
+	}
+	// LICENSE may be among the unread files, so it can't be called missing.
+	if len(tree.Check.Missing) != 0 {
+		t.Errorf("Missing = %v, want none", tree.Check.Missing)
+	}
+}
+
+func TestMaxFilesStatsPartial(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 20; i++ {
+		writeFiles(t, root, "src/"+strconv.Itoa(i)+".go")
+	}
+
+	g := NewGenerator(Options{MaxFiles: 10, MaxDepth: 1, Stats: true})
+	tree, err := g.Walk(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Incomplete != "" {
+		t.Errorf("Incomplete = %q, want empty", tree.Incomplete)
+	}
+	if tree.Partial == "" {
+		t.Error("Partial is empty, want a reason")
+	}
+}
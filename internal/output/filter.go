@@ -0,0 +1,68 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Filter selects which log entries are read. Zero fields match everything.
+// Entries from the typescript carry no exit code or times, so they never
+// match Failed, Since or Until; their directory is taken from the prompt
+// when it shows one.
+type Filter struct {
+	Failed     bool             // only commands that exited non-zero
+	Dir        string           // only commands run in or below this directory
+	Since      time.Time        // only commands started at or after this time
+	Until      time.Time        // only commands started before this time
+	Grep       *regexp.Regexp   // only commands whose text matches
+	OutputGrep *regexp.Regexp   // only commands whose output matches
+	ExcludeCmd []*regexp.Regexp // drop commands whose text matches any of these
+}
+
+// Match reports whether entry passes the filter
+func (f Filter) Match(entry LogEntry) bool {
+	if f.Failed && entry.ExitCode == 0 {
+		return false
+	}
+	if f.Dir != "" && !underDir(entry.WorkingDir, f.Dir) {
+		return false
+	}
+	if !f.Since.IsZero() && (entry.StartTime.IsZero() || entry.StartTime.Before(f.Since)) {
+		return false
+	}
+	if !f.Until.IsZero() && (entry.StartTime.IsZero() || !entry.StartTime.Before(f.Until)) {
+		return false
+	}
+	if f.Grep != nil && !f.Grep.MatchString(entry.Command) {
+		return false
+	}
+	if f.OutputGrep != nil && !f.OutputGrep.MatchString(entry.Output) {
+		return false
+	}
+	for _, re := range f.ExcludeCmd {
+		if re.MatchString(entry.Command) {
+			return false
+		}
+	}
+	return true
+}
+
+// underDir reports whether path is dir or below it. A leading "~" in path,
+// as shown by prompts, is expanded.
+func underDir(path, dir string) bool {
+	if path == "" {
+		return false
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return false
+		}
+		path = filepath.Join(homeDir, path[1:])
+	}
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
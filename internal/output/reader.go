@@ -9,10 +9,16 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/jupiterozeye/context/internal/color"
 )
 
 // LogEntry represents a single logged command with its output
 type LogEntry struct {
+	// ID identifies the entry for `context show` and ranges. Records get a
+	// sequential ID when captured. Typescript and legacy .log entries have
+	// none (0): their position changes as the files are rotated.
+	ID         int
 	Command    string
 	StartTime  time.Time
 	EndTime    time.Time
@@ -24,7 +30,14 @@ type LogEntry struct {
 
 // Options for reading log files
 type Options struct {
-	Format string // raw, markdown, detailed
+	Format         string // raw, markdown, detailed
+	LogDir         string // defaults to ~/.context/logs
+	TypescriptPath string // defaults to ~/.context/typescript
+	Color          bool   // highlight failed commands for terminal display
+	Filter         Filter // entries to read, applied before taking the last n
+	MaxLines       int    // keep the head and tail of longer outputs (0 = unlimited)
+	MaxBytes       int    // likewise, per output in bytes
+	MaxTotalBytes  int    // shrink the largest outputs until the payload fits
 }
 
 // Reader handles reading and parsing log files
@@ -37,25 +50,122 @@ type Reader struct {
 // NewReader creates a new log reader
 func NewReader(opts Options) *Reader {
 	homeDir, _ := os.UserHomeDir()
-	return &Reader{
+	r := &Reader{
 		opts:           opts,
 		logDir:         filepath.Join(homeDir, ".context", "logs"),
 		typescriptPath: filepath.Join(homeDir, ".context", "typescript"),
 	}
+	if opts.LogDir != "" {
+		r.logDir = opts.LogDir
+	}
+	if opts.TypescriptPath != "" {
+		r.typescriptPath = opts.TypescriptPath
+	}
+	return r
 }
 
-// Read retrieves the last n log entries
+// Read retrieves the last n log entries that match Options.Filter, or all of
+// them when n <= 0
 func (r *Reader) Read(n int) ([]LogEntry, error) {
-	// First, try to read from typescript if it exists (has actual output)
+	// Records written by `context record` have the output, exit status and
+	// ID of every shell's commands, so they come first.
+	if r.hasRecords() {
+		return r.readFromLogFiles(n)
+	}
+
+	// Then the typescript of zsh sessions from before records were written
 	if entries, err := r.readFromTypescript(n); err == nil && len(entries) > 0 {
 		return entries, nil
 	}
 
-	// Fall back to log files (command metadata only)
+	// Fall back to legacy log files
 	return r.readFromLogFiles(n)
 }
 
-// readFromLogFiles reads from individual log files
+// hasRecords reports whether the log directory holds any record files.
+func (r *Reader) hasRecords() bool {
+	matches, _ := filepath.Glob(filepath.Join(r.logDir, "*"+recordExt))
+	return len(matches) > 0
+}
+
+// ReadAll retrieves every log entry that matches Options.Filter, oldest first
+func (r *Reader) ReadAll() ([]LogEntry, error) {
+	return r.Read(0)
+}
+
+// FindIDs returns the entries with the given IDs, in that order. It fails on
+// the first ID that doesn't exist.
+func (r *Reader) FindIDs(ids []int) ([]LogEntry, error) {
+	all, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return SelectIDs(all, ids)
+}
+
+// SelectIDs returns the entries with the given IDs, in that order. It fails
+// on the first ID that doesn't exist.
+func SelectIDs(all []LogEntry, ids []int) ([]LogEntry, error) {
+	byID := make(map[int]LogEntry, len(all))
+	for _, e := range all {
+		if e.ID != 0 {
+			byID[e.ID] = e
+		}
+	}
+
+	entries := make([]LogEntry, 0, len(ids))
+	for _, id := range ids {
+		e, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("no command with ID %d", id)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// SelectRange returns the entries from start to end inclusive. Positive
+// bounds are IDs and negative ones count back from the newest entry, so
+// -1 is the last command; entries must be oldest first.
+func SelectRange(entries []LogEntry, start, end int) []LogEntry {
+	from := len(entries)
+	if start < 0 {
+		from = len(entries) + start
+	} else {
+		for i, e := range entries {
+			if e.ID >= start {
+				from = i
+				break
+			}
+		}
+	}
+
+	to := -1
+	if end < 0 {
+		to = len(entries) + end
+	} else {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if entries[i].ID != 0 && entries[i].ID <= end {
+				to = i
+				break
+			}
+		}
+	}
+
+	if from < 0 {
+		from = 0
+	}
+	if to >= len(entries) {
+		to = len(entries) - 1
+	}
+	if from > to {
+		return nil
+	}
+	return entries[from : to+1]
+}
+
+// readFromLogFiles reads from the record files written by `context record`
+// and from legacy .log files
 func (r *Reader) readFromLogFiles(n int) ([]LogEntry, error) {
 	files, err := os.ReadDir(r.logDir)
 	if err != nil {
@@ -68,7 +178,7 @@ func (r *Reader) readFromLogFiles(n int) ([]LogEntry, error) {
 	// Filter and sort log files by name (which includes timestamp)
 	var logFiles []os.DirEntry
 	for _, file := range files {
-		if strings.HasSuffix(file.Name(), ".log") {
+		if strings.HasSuffix(file.Name(), recordExt) || strings.HasSuffix(file.Name(), ".log") {
 			logFiles = append(logFiles, file)
 		}
 	}
@@ -84,9 +194,21 @@ func (r *Reader) readFromLogFiles(n int) ([]LogEntry, error) {
 
 	// Read the last n entries
 	var entries []LogEntry
-	for i := 0; i < n && i < len(logFiles); i++ {
-		entry, err := r.parseLogFile(filepath.Join(r.logDir, logFiles[i].Name()))
-		if err == nil && entry != nil {
+	for i := 0; (n <= 0 || len(entries) < n) && i < len(logFiles); i++ {
+		path := filepath.Join(r.logDir, logFiles[i].Name())
+		parse := r.parseLogFile
+		if strings.HasSuffix(path, recordExt) {
+			parse = parseRecordFile
+		}
+		entry, err := parse(path)
+		if err != nil || entry == nil {
+			continue
+		}
+		if !r.opts.Filter.Since.IsZero() && !entry.StartTime.IsZero() && entry.StartTime.Before(r.opts.Filter.Since) {
+			// Files are sorted newest first, so the rest are older still.
+			break
+		}
+		if r.opts.Filter.Match(*entry) {
 			entries = append(entries, *entry)
 		}
 	}
@@ -99,7 +221,8 @@ func (r *Reader) readFromLogFiles(n int) ([]LogEntry, error) {
 	return entries, nil
 }
 
-// parseLogFile parses a single log file into a LogEntry
+// parseLogFile parses a single legacy log file, with "=== KEY: value" headers
+// written by older shell integration, into a LogEntry
 func (r *Reader) parseLogFile(path string) (*LogEntry, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -131,10 +254,10 @@ func (r *Reader) parseLogFile(path string) (*LogEntry, error) {
 			entry.Command = strings.TrimPrefix(line, "=== COMMAND: ")
 		} else if strings.HasPrefix(line, "=== START_TIME: ") {
 			timeStr := strings.TrimPrefix(line, "=== START_TIME: ")
-			entry.StartTime, _ = time.Parse("2006-01-02 15:04:05", timeStr)
+			entry.StartTime, _ = time.ParseInLocation("2006-01-02 15:04:05", timeStr, time.Local)
 		} else if strings.HasPrefix(line, "=== END_TIME: ") {
 			timeStr := strings.TrimPrefix(line, "=== END_TIME: ")
-			entry.EndTime, _ = time.Parse("2006-01-02 15:04:05", timeStr)
+			entry.EndTime, _ = time.ParseInLocation("2006-01-02 15:04:05", timeStr, time.Local)
 		} else if strings.HasPrefix(line, "=== DURATION: ") {
 			durStr := strings.TrimPrefix(line, "=== DURATION: ")
 			durStr = strings.TrimSuffix(durStr, "s")
@@ -149,9 +272,18 @@ func (r *Reader) parseLogFile(path string) (*LogEntry, error) {
 		}
 	}
 
-	// Clean output - remove ANSI codes and bat warnings
-	output := strings.Join(outputLines, "\n")
+	entry.Output = cleanLogOutput(strings.Join(outputLines, "\n"))
+
+	return entry, nil
+}
+
+// cleanLogOutput removes ANSI codes and bat warnings from captured output
+func cleanLogOutput(output string) string {
 	output = stripANSI(output)
+	// Terminals end lines with \r\n, and progress output rewrites a line
+	// after a lone \r.
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	output = strings.ReplaceAll(output, "\r", "\n")
 	output = strings.TrimSpace(output)
 
 	// Filter out bat warnings
@@ -166,9 +298,7 @@ func (r *Reader) parseLogFile(path string) (*LogEntry, error) {
 		output = strings.Join(filtered, "\n")
 	}
 
-	entry.Output = output
-
-	return entry, nil
+	return output
 }
 
 // readFromTypescript reads from the script typescript file
@@ -182,13 +312,18 @@ func (r *Reader) readFromTypescript(n int) ([]LogEntry, error) {
 		return nil, err
 	}
 
-	entries := r.parseTypescript(string(content))
+	var entries []LogEntry
+	for _, entry := range r.parseTypescript(string(content)) {
+		if r.opts.Filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("no commands in typescript")
 	}
 
 	// Return last n entries
-	if n > len(entries) {
+	if n <= 0 || n > len(entries) {
 		n = len(entries)
 	}
 	return entries[len(entries)-n:], nil
@@ -203,12 +338,12 @@ func (r *Reader) parseTypescript(content string) []LogEntry {
 
 	lines := strings.Split(content, "\n")
 
-	// Prompt patterns
+	// Prompt patterns, capturing the directory when the prompt shows it
 	promptPatterns := []*regexp.Regexp{
-		regexp.MustCompile(`[~\/][^\s]*\s*❯\s*(.+)$`),
-		regexp.MustCompile(`[~\/][^\s]*\s*>\s+(.+)$`),
-		regexp.MustCompile(`\$\s+(.+)$`),
-		regexp.MustCompile(`%\s+(.+)$`),
+		regexp.MustCompile(`([~\/][^\s]*)\s*❯\s*(.+)$`),
+		regexp.MustCompile(`([~\/][^\s]*)\s*>\s+(.+)$`),
+		regexp.MustCompile(`()\$\s+(.+)$`),
+		regexp.MustCompile(`()%\s+(.+)$`),
 	}
 
 	var currentEntry *LogEntry
@@ -223,12 +358,12 @@ func (r *Reader) parseTypescript(content string) []LogEntry {
 		}
 
 		// Check for command prompt
-		var command string
+		var command, dir string
 		for _, pattern := range promptPatterns {
-			if matches := pattern.FindStringSubmatch(line); len(matches) > 1 {
-				cmd := strings.TrimSpace(matches[1])
+			if matches := pattern.FindStringSubmatch(line); len(matches) > 2 {
+				cmd := strings.TrimSpace(matches[2])
 				if len(cmd) > 1 && !strings.HasPrefix(cmd, "context") {
-					command = cmd
+					command, dir = cmd, matches[1]
 					break
 				}
 			}
@@ -239,7 +374,7 @@ func (r *Reader) parseTypescript(content string) []LogEntry {
 				currentEntry.Output = cleanOutput(strings.Join(outputLines, "\n"))
 				entries = append(entries, *currentEntry)
 			}
-			currentEntry = &LogEntry{Command: command}
+			currentEntry = &LogEntry{Command: command, WorkingDir: dir}
 			outputLines = []string{}
 		} else if currentEntry != nil && !strings.HasPrefix(line, "Copied to clipboard") {
 			outputLines = append(outputLines, line)
@@ -254,9 +389,13 @@ func (r *Reader) parseTypescript(content string) []LogEntry {
 	return entries
 }
 
+// ansiRegex matches control sequences, including private ones such as
+// zsh's \x1b[?2004h, operating system commands such as window titles,
+// character set selections and keypad modes.
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)|\x1b[PX^_][^\x1b]*\x1b\\|\x1b[()][0-9A-Za-z]|\x1b[=>]`)
+
 // stripANSI removes ANSI escape codes
 func stripANSI(s string) string {
-	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]|\x1b\][^\x07]*\x07|\x1b[PX^_][^\x1b]*\x1b\\|\x1b\[[0-9;]*[mKHJPq]`)
 	return ansiRegex.ReplaceAllString(s, "")
 }
 
@@ -269,8 +408,13 @@ func cleanOutput(s string) string {
 	return s
 }
 
-// FormatEntries formats log entries
+// FormatEntries formats log entries, shortening long outputs to the
+// configured limits
 func (r *Reader) FormatEntries(entries []LogEntry) string {
+	return r.format(r.truncate(entries))
+}
+
+func (r *Reader) format(entries []LogEntry) string {
 	var result strings.Builder
 
 	for i, entry := range entries {
@@ -287,8 +431,17 @@ func (r *Reader) FormatEntries(entries []LogEntry) string {
 	return result.String()
 }
 
+// highlightFailure colours text red when colour is enabled and the entry
+// exited with a non-zero code.
+func (r *Reader) highlightFailure(entry LogEntry, text string) string {
+	if !r.opts.Color || entry.ExitCode == 0 {
+		return text
+	}
+	return color.Paint(color.Red, text)
+}
+
 func (r *Reader) formatRaw(result *strings.Builder, entry LogEntry) {
-	result.WriteString(fmt.Sprintf("$ %s\n", entry.Command))
+	result.WriteString(r.highlightFailure(entry, "$ "+entry.Command) + "\n")
 	if entry.Output != "" {
 		result.WriteString(entry.Output)
 		result.WriteString("\n")
@@ -296,7 +449,7 @@ func (r *Reader) formatRaw(result *strings.Builder, entry LogEntry) {
 }
 
 func (r *Reader) formatMarkdown(result *strings.Builder, entry LogEntry, num int) {
-	result.WriteString(fmt.Sprintf("### Command %d\n\n", num))
+	result.WriteString(r.highlightFailure(entry, fmt.Sprintf("### Command %d%s", num, idSuffix(entry))) + "\n\n")
 	result.WriteString(fmt.Sprintf("```bash\n$ %s\n", entry.Command))
 	if entry.Output != "" {
 		result.WriteString(entry.Output)
@@ -306,12 +459,14 @@ func (r *Reader) formatMarkdown(result *strings.Builder, entry LogEntry, num int
 }
 
 func (r *Reader) formatDetailed(result *strings.Builder, entry LogEntry, num int) {
-	result.WriteString(fmt.Sprintf("Command %d: %s\n", num, entry.Command))
+	// Continuation lines of a multi-line command are indented like output.
+	command := strings.ReplaceAll(entry.Command, "\n", "\n    ")
+	result.WriteString(fmt.Sprintf("Command %d%s: %s\n", num, idSuffix(entry), command))
 	if entry.WorkingDir != "" {
 		result.WriteString(fmt.Sprintf("  Directory: %s\n", entry.WorkingDir))
 	}
 	if entry.ExitCode != 0 {
-		result.WriteString(fmt.Sprintf("  Exit Code: %d\n", entry.ExitCode))
+		result.WriteString("  " + r.highlightFailure(entry, fmt.Sprintf("Exit Code: %d", entry.ExitCode)) + "\n")
 	}
 	if entry.Output != "" {
 		result.WriteString("  Output:\n")
@@ -323,3 +478,11 @@ func (r *Reader) formatDetailed(result *strings.Builder, entry LogEntry, num int
 	}
 	result.WriteString("\n")
 }
+
+// idSuffix labels an entry with its ID, e.g. " (#42)", when it has one.
+func idSuffix(entry LogEntry) string {
+	if entry.ID == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (#%d)", entry.ID)
+}
@@ -0,0 +1,193 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jupiterozeye/context/internal/atomicfile"
+)
+
+// RecordVersion is the version of the record format written by WriteRecord
+const RecordVersion = 1
+
+// recordExt is the extension of record files in the log directory
+const recordExt = ".rec"
+
+// recordHeader is the first line of a record file. The command's raw output
+// follows it unchanged, so neither side needs escaping.
+type recordHeader struct {
+	Version    int       `json:"version"`
+	ID         int       `json:"id,omitempty"`
+	Command    string    `json:"command"`
+	StartTime  time.Time `json:"start_time"` // RFC 3339 with nanoseconds
+	EndTime    time.Time `json:"end_time"`
+	DurationNS int64     `json:"duration_ns"`
+	ExitCode   int       `json:"exit_code"`
+	WorkingDir string    `json:"working_dir,omitempty"`
+	Shell      string    `json:"shell,omitempty"`
+}
+
+// WriteRecord saves entry in logDir as a new record file, with the next
+// sequential ID, and returns its path. Files are named after the local start
+// time, like the legacy .log files, so both sort chronologically, and are
+// written atomically so readers never see a partial record. Records hold
+// raw command output, so only the user can read them.
+func WriteRecord(logDir string, entry LogEntry, shell string) (string, error) {
+	if err := os.MkdirAll(logDir, 0o700); err != nil {
+		return "", err
+	}
+
+	id, err := nextID(logDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to assign ID: %w", err)
+	}
+
+	header, err := json.Marshal(recordHeader{
+		Version:    RecordVersion,
+		ID:         id,
+		Command:    entry.Command,
+		StartTime:  entry.StartTime,
+		EndTime:    entry.EndTime,
+		DurationNS: int64(entry.Duration),
+		ExitCode:   entry.ExitCode,
+		WorkingDir: entry.WorkingDir,
+		Shell:      shell,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var data bytes.Buffer
+	data.Write(header)
+	data.WriteByte('\n')
+	data.WriteString(entry.Output)
+
+	name := entry.StartTime.Local().Format("20060102_150405.000000000") + "_" + sanitizeCommand(entry.Command) + recordExt
+	path := filepath.Join(logDir, name)
+	if err := atomicfile.Write(path, data.Bytes(), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// nextID allocates the next record ID from the counter file in logDir. The
+// counter is locked so shells finishing commands at once get distinct IDs,
+// and starts after the highest existing ID if it's missing.
+func nextID(logDir string) (int, error) {
+	lock := filepath.Join(logDir, "next-id.lock")
+	for attempt := 0; ; attempt++ {
+		file, err := os.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			file.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return 0, err
+		}
+		// A lock left behind by a killed process is stale after a moment.
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > 2*time.Second {
+			os.Remove(lock)
+			continue
+		}
+		if attempt >= 200 {
+			return 0, fmt.Errorf("%s is locked", lock)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer os.Remove(lock)
+
+	counter := filepath.Join(logDir, "next-id")
+	id := 0
+	if data, err := os.ReadFile(counter); err == nil {
+		id, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if id <= 0 {
+		id = maxRecordID(logDir) + 1
+	}
+
+	if err := atomicfile.Write(counter, []byte(strconv.Itoa(id+1)+"\n"), 0o600); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// maxRecordID returns the highest ID among the records in logDir.
+func maxRecordID(logDir string) int {
+	files, err := os.ReadDir(logDir)
+	if err != nil {
+		return 0
+	}
+	highest := 0
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), recordExt) {
+			continue
+		}
+		if entry, err := parseRecordFile(filepath.Join(logDir, file.Name())); err == nil && entry.ID > highest {
+			highest = entry.ID
+		}
+	}
+	return highest
+}
+
+// parseRecordFile reads a record written by WriteRecord.
+func parseRecordFile(path string) (*LogEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	line, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	var header recordHeader
+	if err := json.Unmarshal(line, &header); err != nil {
+		return nil, fmt.Errorf("invalid record %s: %w", path, err)
+	}
+	if header.Version < 1 || header.Version > RecordVersion {
+		return nil, fmt.Errorf("unsupported record version %d in %s", header.Version, path)
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LogEntry{
+		ID:         header.ID,
+		Command:    header.Command,
+		StartTime:  header.StartTime,
+		EndTime:    header.EndTime,
+		Duration:   time.Duration(header.DurationNS),
+		ExitCode:   header.ExitCode,
+		WorkingDir: header.WorkingDir,
+		Output:     cleanLogOutput(string(body)),
+	}, nil
+}
+
+// sanitizeCommand makes a short, filesystem-safe name from a command line,
+// like the shell integration used to.
+func sanitizeCommand(command string) string {
+	var name strings.Builder
+	for _, r := range command {
+		if name.Len() >= 50 {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			name.WriteRune(r)
+		}
+	}
+	return name.String()
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordFromTypescript(t *testing.T) {
+	// Output captured by zsh's script(1), as context record reads it with
+	// --output-file and --output-offset.
+	captured, err := os.ReadFile("testdata/typescript")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	path, err := WriteRecord(t.TempDir(), LogEntry{
+		Command:   "printf 'line1\\r\\nline2\\r\\n'",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Output:    string(captured),
+	}, "zsh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := parseRecordFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "line1\nline2\nok\ndone"; entry.Output != want {
+		t.Errorf("Output = %q, want %q", entry.Output, want)
+	}
+	if entry.ID != 1 {
+		t.Errorf("ID = %d, want 1", entry.ID)
+	}
+}
+
+func TestCleanLogOutput(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"crlf", "a\r\nb\r\n", "a\nb"},
+		{"lone cr", "10%\r50%\r100%\n", "10%\n50%\n100%"},
+		{"colors", "\x1b[31merror\x1b[0m: x", "error: x"},
+		{"private mode", "\x1b[?2004lout\x1b[?25h", "out"},
+		{"title", "\x1b]0;vim\x1b\\text", "text"},
+		{"bat warning", "one\n[bat warning]: x\ntwo", "one\ntwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanLogOutput(tt.in); got != tt.want {
+				t.Errorf("cleanLogOutput(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDetailedMultilineCommand(t *testing.T) {
+	reader := NewReader(Options{Format: "detailed"})
+	got := reader.FormatEntries([]LogEntry{{ID: 7, Command: "for f in *; do\n  echo $f\ndone", Output: "a\nb"}})
+	want := "Command 1 (#7): for f in *; do\n      echo $f\n    done\n  Output:\n    a\n    b\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("FormatEntries =\n%s\nwant it to contain\n%s", got, want)
+	}
+}
@@ -0,0 +1,165 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jupiterozeye/context/internal/color"
+)
+
+// Hit is a log entry that matched a search
+type Hit struct {
+	Entry     LogEntry
+	Source    string    // "log" or "typescript"
+	InCommand bool      // the command line matched
+	Snippets  []Snippet // matching output lines with their surroundings
+	when      time.Time // for ranking; typescript entries only have an order
+}
+
+// Snippet is a run of output lines around one or more matches
+type Snippet struct {
+	Start int // line number of Lines[0], from 1
+	Lines []string
+}
+
+// SearchOptions configures Search
+type SearchOptions struct {
+	Context  int // lines shown before and after each matching line
+	Snippets int // maximum snippets per hit, 0 = unlimited
+}
+
+// Search looks for query in the command lines and outputs of every retained
+// log record, or of the typescript when there are none, and returns the
+// matches newest first.
+func (r *Reader) Search(query *regexp.Regexp, opts SearchOptions) ([]Hit, error) {
+	var hits []Hit
+
+	logs, logErr := r.readFromLogFiles(0)
+	for _, e := range logs {
+		if hit, ok := match(e, query, opts); ok {
+			hit.Source = "log"
+			hit.when = e.StartTime
+			hits = append(hits, hit)
+		}
+	}
+
+	// zsh records repeat the typescript, so it is only searched without
+	// records.
+	var tsErr error
+	if r.hasRecords() {
+		tsErr = fmt.Errorf("typescript superseded by records")
+	} else if typescript, err := r.readFromTypescript(0); err != nil {
+		tsErr = err
+	} else {
+		// Place typescript commands by the time it was last written, keeping
+		// their order.
+		modified := time.Now()
+		if info, err := os.Stat(r.typescriptPath); err == nil {
+			modified = info.ModTime()
+		}
+		for i, e := range typescript {
+			if hit, ok := match(e, query, opts); ok {
+				hit.Source = "typescript"
+				hit.when = modified.Add(-time.Duration(len(typescript)-i) * time.Nanosecond)
+				hits = append(hits, hit)
+			}
+		}
+	}
+
+	if logErr != nil && tsErr != nil {
+		return nil, logErr
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].when.After(hits[j].when)
+	})
+	return hits, nil
+}
+
+// match reports whether entry matches query and collects output snippets.
+func match(entry LogEntry, query *regexp.Regexp, opts SearchOptions) (Hit, bool) {
+	hit := Hit{Entry: entry, InCommand: query.MatchString(entry.Command)}
+
+	lines := strings.Split(entry.Output, "\n")
+	var current *Snippet
+	end := -1 // last line index included in current
+	for i, line := range lines {
+		if !query.MatchString(line) {
+			continue
+		}
+		from := max(i-opts.Context, 0)
+		to := min(i+opts.Context, len(lines)-1)
+		if current != nil && from <= end+1 {
+			current.Lines = append(current.Lines, lines[end+1:to+1]...)
+			end = to
+			continue
+		}
+		if opts.Snippets > 0 && len(hit.Snippets) == opts.Snippets {
+			break
+		}
+		hit.Snippets = append(hit.Snippets, Snippet{Start: from + 1, Lines: append([]string(nil), lines[from:to+1]...)})
+		current = &hit.Snippets[len(hit.Snippets)-1]
+		end = to
+	}
+
+	return hit, hit.InCommand || len(hit.Snippets) > 0
+}
+
+// FormatHits lists search results with their number, which selects them for
+// copying, and highlights matches when colour is enabled.
+func (r *Reader) FormatHits(hits []Hit, query *regexp.Regexp) string {
+	var result strings.Builder
+
+	highlight := func(text string) string {
+		if !r.opts.Color {
+			return text
+		}
+		return query.ReplaceAllStringFunc(text, func(m string) string {
+			return color.Paint(color.Yellow, m)
+		})
+	}
+
+	for i, hit := range hits {
+		e := hit.Entry
+		meta := []string{fmt.Sprintf("[%d]", i+1)}
+		if e.ID != 0 {
+			meta = append(meta, fmt.Sprintf("#%d", e.ID))
+		}
+		if !e.StartTime.IsZero() {
+			meta = append(meta, e.StartTime.Local().Format("2006-01-02 15:04"))
+		} else {
+			meta = append(meta, hit.Source)
+		}
+		if e.WorkingDir != "" {
+			meta = append(meta, e.WorkingDir)
+		}
+		if e.ExitCode != 0 {
+			meta = append(meta, r.highlightFailure(e, fmt.Sprintf("exit %d", e.ExitCode)))
+		}
+		result.WriteString(strings.Join(meta, "  ") + "\n")
+
+		command := strings.ReplaceAll(e.Command, "\n", "\n      ")
+		result.WriteString("    $ " + highlight(command) + "\n")
+
+		width := 0
+		if n := len(hit.Snippets); n > 0 {
+			last := hit.Snippets[n-1]
+			width = len(fmt.Sprint(last.Start + len(last.Lines) - 1))
+		}
+		for j, s := range hit.Snippets {
+			if j > 0 {
+				result.WriteString("    " + strings.Repeat(" ", width) + "  ...\n")
+			}
+			for k, line := range s.Lines {
+				result.WriteString(fmt.Sprintf("    %*d: %s\n", width, s.Start+k, highlight(line)))
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// truncate shortens entries' outputs to Options.MaxLines and MaxBytes each,
+// then, if the formatted payload would exceed MaxTotalBytes, shrinks the
+// largest outputs first until it fits.
+func (r *Reader) truncate(entries []LogEntry) []LogEntry {
+	if r.opts.MaxLines <= 0 && r.opts.MaxBytes <= 0 && r.opts.MaxTotalBytes <= 0 {
+		return entries
+	}
+
+	// Sizes are measured without colour so the display and the copied
+	// payload are shortened the same way.
+	plain := *r
+	plain.opts.Color = false
+
+	shortened := make([]LogEntry, len(entries))
+	for i, e := range entries {
+		e.Output = truncateLines(e.Output, r.opts.MaxLines)
+		e.Output = truncateBytes(e.Output, r.opts.MaxBytes)
+		shortened[i] = e
+	}
+
+	if r.opts.MaxTotalBytes <= 0 || len(plain.format(shortened)) <= r.opts.MaxTotalBytes {
+		return shortened
+	}
+
+	// Everything but the outputs is kept, so only the rest can be shared out.
+	bare := make([]LogEntry, len(shortened))
+	sizes := make([]int, len(shortened))
+	for i, e := range shortened {
+		bare[i] = e
+		bare[i].Output = ""
+		sizes[i] = len(e.Output)
+	}
+	budget := r.opts.MaxTotalBytes - len(plain.format(bare))
+
+	capped := make([]LogEntry, len(shortened))
+	for limit := fairShare(sizes, budget); ; limit = limit * 9 / 10 {
+		for i, e := range shortened {
+			e.Output = truncateBytes(e.Output, max(limit, 1))
+			capped[i] = e
+		}
+		// Omission markers take some room of their own.
+		if limit <= 1 || len(plain.format(capped)) <= r.opts.MaxTotalBytes {
+			return capped
+		}
+	}
+}
+
+// fairShare returns the largest per-output limit such that the capped sizes
+// add up to no more than budget. Outputs below the limit are left whole.
+func fairShare(sizes []int, budget int) int {
+	sorted := append([]int(nil), sizes...)
+	sort.Ints(sorted)
+
+	remaining := budget
+	for i, size := range sorted {
+		share := remaining / (len(sorted) - i)
+		if size > share {
+			return max(share, 0)
+		}
+		remaining -= size
+	}
+	return sorted[len(sorted)-1]
+}
+
+// truncateLines keeps the first and last lines of s so that at most maxLines
+// remain, with a marker in place of the rest.
+func truncateLines(s string, maxLines int) string {
+	if maxLines <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) <= maxLines {
+		return s
+	}
+
+	head := (maxLines + 1) / 2
+	tail := maxLines - head
+	return joinTruncated(lines[:head], lines[len(lines)-tail:], omittedLines(len(lines)-head-tail))
+}
+
+// truncateBytes keeps whole lines from the start and end of s within maxBytes,
+// with a marker in place of the rest. A single line that is too long is cut
+// mid-line.
+func truncateBytes(s string, maxBytes int) string {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	headBudget := (maxBytes + 1) / 2
+	tailBudget := maxBytes - headBudget
+
+	var head, tail []string
+	used := 0
+	for _, line := range lines {
+		if used+len(line)+1 > headBudget {
+			break
+		}
+		head = append(head, line)
+		used += len(line) + 1
+	}
+	used = 0
+	for i := len(lines) - 1; i >= len(head); i-- {
+		if used+len(lines[i])+1 > tailBudget {
+			break
+		}
+		tail = append([]string{lines[i]}, tail...)
+		used += len(lines[i]) + 1
+	}
+
+	if omitted := len(lines) - len(head) - len(tail); omitted > 0 && (len(head) > 0 || len(tail) > 0) {
+		return joinTruncated(head, tail, omittedLines(omitted))
+	}
+
+	// Nothing fits as whole lines, so cut through the text itself.
+	start := s[:headBudget]
+	for !utf8.ValidString(start) && len(start) > 0 {
+		start = start[:len(start)-1]
+	}
+	end := s[len(s)-tailBudget:]
+	for !utf8.ValidString(end) && len(end) > 0 {
+		end = end[1:]
+	}
+	marker := "[... " + formatCount(len(s)-len(start)-len(end)) + " bytes omitted ...]"
+	return start + marker + end
+}
+
+func joinTruncated(head, tail []string, marker string) string {
+	parts := append(append(append([]string(nil), head...), marker), tail...)
+	return strings.Join(parts, "\n")
+}
+
+func omittedLines(n int) string {
+	unit := "lines"
+	if n == 1 {
+		unit = "line"
+	}
+	return "[... " + formatCount(n) + " " + unit + " omitted ...]"
+}
+
+// formatCount writes n with thousands separators, e.g. 1,284.
+func formatCount(n int) string {
+	digits := strconv.Itoa(n)
+	var result strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result.WriteByte(',')
+		}
+		result.WriteRune(d)
+	}
+	return result.String()
+}
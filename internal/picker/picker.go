@@ -0,0 +1,468 @@
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jupiterozeye/context/internal/dir"
+)
+
+// ErrCancelled is returned by Run when the user quits without confirming.
+var ErrCancelled = errors.New("selection cancelled")
+
+// Result is the outcome of a confirmed picker session
+type Result struct {
+	Selected        map[string]bool // Entry.Path of each selected file
+	IncludeContents bool
+}
+
+// row is a visible line in the picker
+type row struct {
+	entry *dir.Entry
+	depth int
+}
+
+type model struct {
+	tree      *dir.Tree
+	expanded  map[string]bool
+	selected  map[string]bool
+	contents  bool
+	filter    string
+	filtering bool
+	rows      []row
+	cursor    int
+	offset    int
+}
+
+// Run shows a full-screen tree browser for tree on the controlling terminal
+// and blocks until the user confirms or cancels the selection.
+func Run(tree *dir.Tree, includeContents bool) (*Result, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("interactive mode needs a terminal: %w", err)
+	}
+	defer tty.Close()
+
+	restore, err := makeRaw(tty)
+	if err != nil {
+		return nil, fmt.Errorf("cannot set up terminal: %w", err)
+	}
+	defer restore()
+
+	// Alternate screen, hidden cursor; undone on exit.
+	fmt.Fprint(tty, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(tty, "\x1b[?25h\x1b[?1049l")
+
+	m := &model{
+		tree:     tree,
+		expanded: make(map[string]bool),
+		selected: make(map[string]bool),
+		contents: includeContents,
+	}
+	m.rebuild()
+
+	buf := make([]byte, 32)
+	for {
+		width, height := termSize(tty)
+		fmt.Fprint(tty, m.render(width, height))
+
+		n, err := tty.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range splitKeys(buf[:n]) {
+			switch m.handle(key, height) {
+			case actionConfirm:
+				return &Result{Selected: m.selected, IncludeContents: m.contents}, nil
+			case actionCancel:
+				return nil, ErrCancelled
+			}
+		}
+	}
+}
+
+// splitKeys splits raw terminal input into keys: escape sequences such as
+// "\x1b[A", lone escapes and single runes.
+func splitKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		size := 1
+		switch {
+		case input[0] == 0x1b && len(input) > 2 && (input[1] == '[' || input[1] == 'O'):
+			// CSI/SS3 sequences end with a byte in the range @ to ~.
+			size = 2
+			for size < len(input) {
+				size++
+				if c := input[size-1]; c >= 0x40 && c <= 0x7e {
+					break
+				}
+			}
+		case input[0] >= utf8.RuneSelf:
+			_, size = utf8.DecodeRune(input)
+		}
+		keys = append(keys, string(input[:size]))
+		input = input[size:]
+	}
+	return keys
+}
+
+type action int
+
+const (
+	actionNone action = iota
+	actionConfirm
+	actionCancel
+)
+
+// handle applies a key press and reports whether the session should end.
+func (m *model) handle(key string, height int) action {
+	if m.filtering {
+		switch key {
+		case "\r", "\n":
+			m.filtering = false
+		case "\x1b":
+			m.filtering = false
+			m.filter = ""
+			m.rebuild()
+		case "\x7f", "\b":
+			if m.filter != "" {
+				_, size := utf8.DecodeLastRuneInString(m.filter)
+				m.filter = m.filter[:len(m.filter)-size]
+				m.rebuild()
+			}
+		case "\x03":
+			return actionCancel
+		default:
+			if utf8.ValidString(key) && key >= " " && key != "\x7f" {
+				m.filter += key
+				m.cursor = 0
+				m.rebuild()
+			}
+		}
+		return actionNone
+	}
+
+	page := height - 3
+	if page < 1 {
+		page = 1
+	}
+
+	switch key {
+	case "q", "\x1b", "\x03":
+		return actionCancel
+	case "\r", "\n":
+		return actionConfirm
+	case "\x1b[A", "k":
+		m.move(-1)
+	case "\x1b[B", "j":
+		m.move(1)
+	case "\x1b[5~":
+		m.move(-page)
+	case "\x1b[6~":
+		m.move(page)
+	case "\x1b[H", "g":
+		m.move(-len(m.rows))
+	case "\x1b[F", "G":
+		m.move(len(m.rows))
+	case "\x1b[C", "l":
+		if r, ok := m.current(); ok && r.entry.IsDir {
+			m.expanded[r.entry.Path] = true
+			m.rebuild()
+		}
+	case "\x1b[D", "h":
+		m.collapse()
+	case " ":
+		if r, ok := m.current(); ok {
+			m.toggle(r.entry)
+		}
+	case "a":
+		m.toggleAll()
+	case "c":
+		m.contents = !m.contents
+	case "/":
+		m.filtering = true
+	}
+
+	return actionNone
+}
+
+func (m *model) current() (row, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return row{}, false
+	}
+	return m.rows[m.cursor], true
+}
+
+func (m *model) move(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// collapse closes the directory under the cursor, or jumps to its parent.
+func (m *model) collapse() {
+	r, ok := m.current()
+	if !ok {
+		return
+	}
+	if r.entry.IsDir && m.expanded[r.entry.Path] {
+		delete(m.expanded, r.entry.Path)
+		m.rebuild()
+		return
+	}
+	for i := m.cursor - 1; i >= 0; i-- {
+		if m.rows[i].depth < r.depth {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// toggle selects a file, or every file below a directory. A directory whose
+// files are all selected is deselected instead.
+func (m *model) toggle(e *dir.Entry) {
+	files := filesBelow(e)
+	all := true
+	for _, f := range files {
+		if !m.selected[f.Path] {
+			all = false
+			break
+		}
+	}
+	for _, f := range files {
+		if all {
+			delete(m.selected, f.Path)
+		} else {
+			m.selected[f.Path] = true
+		}
+	}
+}
+
+// toggleAll selects every file matching the filter (all files without one),
+// or clears them if they are all selected already.
+func (m *model) toggleAll() {
+	matching := dir.Entry{IsDir: true}
+	for _, f := range filesBelow(&dir.Entry{IsDir: true, Children: m.tree.Entries}) {
+		if m.filter == "" || fuzzyMatch(m.filter, m.rel(f.Path)) {
+			matching.Children = append(matching.Children, *f)
+		}
+	}
+	m.toggle(&matching)
+}
+
+func filesBelow(e *dir.Entry) []*dir.Entry {
+	if !e.IsDir {
+		return []*dir.Entry{e}
+	}
+	var files []*dir.Entry
+	for i := range e.Children {
+		files = append(files, filesBelow(&e.Children[i])...)
+	}
+	return files
+}
+
+// rebuild recomputes the visible rows from the expansion state and filter.
+func (m *model) rebuild() {
+	m.rows = m.rows[:0]
+	m.addRows(m.tree.Entries, 0)
+	m.move(0)
+}
+
+func (m *model) addRows(entries []dir.Entry, depth int) {
+	for i := range entries {
+		e := &entries[i]
+
+		if m.filter != "" && !m.matches(e) {
+			continue
+		}
+
+		m.rows = append(m.rows, row{entry: e, depth: depth})
+
+		if e.IsDir && (m.filter != "" || m.expanded[e.Path]) {
+			m.addRows(e.Children, depth+1)
+		}
+	}
+}
+
+// matches reports whether e or any of its descendants fuzzy-matches the
+// filter.
+func (m *model) matches(e *dir.Entry) bool {
+	if fuzzyMatch(m.filter, m.rel(e.Path)) {
+		return true
+	}
+	for i := range e.Children {
+		if m.matches(&e.Children[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) rel(path string) string {
+	rel, err := filepath.Rel(m.tree.Root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in order in s,
+// ignoring case.
+func fuzzyMatch(pattern, s string) bool {
+	s = strings.ToLower(s)
+	for _, r := range strings.ToLower(pattern) {
+		i := strings.IndexRune(s, r)
+		if i < 0 {
+			return false
+		}
+		s = s[i+utf8.RuneLen(r):]
+	}
+	return true
+}
+
+// mark returns the checkbox for an entry: [x] selected, [-] partially
+// selected directory, [ ] unselected.
+func (m *model) mark(e *dir.Entry) string {
+	files := filesBelow(e)
+	count := 0
+	for _, f := range files {
+		if m.selected[f.Path] {
+			count++
+		}
+	}
+	switch {
+	case count > 0 && count == len(files):
+		return "[x]"
+	case count > 0:
+		return "[-]"
+	default:
+		return "[ ]"
+	}
+}
+
+// estimate returns the number of selected files, their total size and an
+// approximate token count for the resulting payload.
+func (m *model) estimate() (int, int64, int) {
+	var size int64
+	var treeBytes int
+	for path := range m.selected {
+		rel := m.rel(path)
+		treeBytes += len(rel) + 4*strings.Count(rel, "/") + 5
+	}
+	for _, f := range filesBelow(&dir.Entry{IsDir: true, Children: m.tree.Entries}) {
+		if m.selected[f.Path] {
+			size += f.Size
+		}
+	}
+
+	payload := int64(treeBytes)
+	if m.contents {
+		payload += size
+	}
+	// Roughly four bytes per token for English text and code.
+	return len(m.selected), size, int(payload / 4)
+}
+
+func (m *model) render(width, height int) string {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+
+	contents := "off"
+	if m.contents {
+		contents = "on"
+	}
+	header := fmt.Sprintf("%s/  space select  a all  ←/→ fold  / filter  c contents:%s  enter confirm  q quit",
+		m.tree.Name, contents)
+	b.WriteString("\x1b[7m" + fit(header, width) + "\x1b[0m\r\n")
+
+	visible := height - 2
+	if visible < 1 {
+		visible = 1
+	}
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+
+	for i := m.offset; i < len(m.rows) && i < m.offset+visible; i++ {
+		r := m.rows[i]
+
+		fold := "  "
+		name := r.entry.Name
+		if r.entry.IsDir {
+			fold = "▸ "
+			if m.filter != "" || m.expanded[r.entry.Path] {
+				fold = "▾ "
+			}
+			name += "/"
+		}
+		if r.entry.Secret {
+			name += " [secret]"
+		}
+
+		line := fit(strings.Repeat("  ", r.depth)+fold+m.mark(r.entry)+" "+name, width)
+		if i == m.cursor {
+			line = "\x1b[7m" + line + "\x1b[0m"
+		}
+		b.WriteString(line + "\r\n")
+	}
+
+	for i := len(m.rows) - m.offset; i < visible; i++ {
+		b.WriteString("\r\n")
+	}
+
+	files, size, tokens := m.estimate()
+	status := fmt.Sprintf("%d files · %s · ~%s tokens", files, humanBytes(size), humanCount(tokens))
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "█"
+		}
+		status = "filter: " + m.filter + cursor + "  │  " + status
+	}
+	b.WriteString("\x1b[7m" + fit(status, width) + "\x1b[0m")
+
+	return b.String()
+}
+
+// fit truncates or pads s to exactly width runes.
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		runes := []rune(s)
+		if width > 1 {
+			return string(runes[:width-1]) + "…"
+		}
+		return string(runes[:width])
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+func humanBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func humanCount(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
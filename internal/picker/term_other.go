@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package picker
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func makeRaw(f *os.File) (func(), error) {
+	return nil, fmt.Errorf("interactive mode is not supported on %s", runtime.GOOS)
+}
+
+func termSize(f *os.File) (int, int) {
+	return 80, 24
+}
@@ -0,0 +1,314 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jupiterozeye/context/internal/config"
+)
+
+// Rule replaces matches of Pattern, or of its first capture group when it
+// has one, with a "[REDACTED:name]" marker
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// builtin are the detectors applied unless disabled in the config. More
+// specific rules come first so their name is the one reported.
+var builtin = []Rule{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|AIPA|ANPA|ANVA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`)},
+	{"gcp-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{"gcp-oauth-token", regexp.MustCompile(`\bya29\.[0-9A-Za-z_\-]{20,}`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"slack-token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"slack-webhook", regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{10,}`)},
+	{"authorization", regexp.MustCompile(`(?i)\bauthorization["']?\s*[:=]\s*["']?(?:bearer|basic|token)\s+([A-Za-z0-9._~+/=-]{8,})`)},
+	{"url-password", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`)},
+	{"password", regexp.MustCompile(`(?i)\b(?:[a-z0-9]+_)?(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|client[_-]?secret)["']?\s*[:=]\s*["']?([^\s"'&,;$({\[<][^\s"'&,;(){}\[\]<>]{3,})`)},
+}
+
+// plausible holds extra checks on the values matched by some built-in
+// rules, to tell secrets from source code that merely names them.
+var plausible = map[string]func(key, value string) bool{
+	"password": plausiblePassword,
+}
+
+// codeLiterals are values that appear after "password =" and the like in
+// source code and configs without being secrets.
+var codeLiterals = map[string]bool{
+	"true": true, "false": true, "nil": true, "null": true, "none": true,
+	"undefined": true, "yes": true, "no": true, "on": true, "off": true,
+	"required": true, "optional": true, "string": true,
+}
+
+// selector matches dotted references such as cfg.APIKey or os.Args.
+var selector = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)+$`)
+
+// plausiblePassword reports whether value, assigned to key, could be a
+// password rather than code: not a literal such as true or nil, not a
+// selector such as cfg.APIKey, and not the key's own name, as in
+// "Password: password".
+func plausiblePassword(key, value string) bool {
+	lower := strings.ToLower(value)
+	return !codeLiterals[lower] && !selector.MatchString(value) && lower != strings.ToLower(key)
+}
+
+// highEntropyName is the name of the detector for random-looking strings
+const highEntropyName = "high-entropy"
+
+// candidate matches strings that could be random tokens, including base64
+var candidate = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+// checksum matches the end of the text before a public checksum: go.sum's
+// h1: hashes and Subresource Integrity values, as in package-lock.json
+// integrity fields or a flake.nix vendorHash. They pin dependencies rather
+// than grant access, and the candidate itself may start with the sha512-
+// prefix.
+var checksum = regexp.MustCompile(`(?:\bh1:|\bsha(?:256|384|512)-|(?i:integrity)["']?\s*[:=]\s*["']?)$`)
+
+// sri matches Subresource Integrity prefixes at the start of a candidate.
+var sri = regexp.MustCompile(`^sha(?:256|384|512)-`)
+
+// word matches the words of camelCase and PascalCase identifiers.
+var word = regexp.MustCompile(`[A-Z]?[a-z]{3,}`)
+
+// Redactor removes secrets from text and counts what it removed
+type Redactor struct {
+	rules       []Rule
+	allow       []*regexp.Regexp
+	highEntropy bool
+	counts      map[string]int
+}
+
+// New returns a Redactor with the built-in detectors and the rules and
+// allowlist from cfg. It returns nil, which redacts nothing, if cfg
+// disables redaction.
+func New(cfg config.Redact) (*Redactor, error) {
+	if cfg.Disabled {
+		return nil, nil
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range cfg.DisableBuiltin {
+		disabled[name] = true
+	}
+
+	r := &Redactor{highEntropy: !disabled[highEntropyName], counts: make(map[string]int)}
+	for _, rule := range builtin {
+		if !disabled[rule.Name] {
+			r.rules = append(r.rules, rule)
+		}
+	}
+	for _, rule := range cfg.Rules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact rule %q: %w", rule.Name, err)
+		}
+		name := rule.Name
+		if name == "" {
+			name = "custom"
+		}
+		r.rules = append(r.rules, Rule{name, re})
+	}
+	for _, pattern := range cfg.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact allow pattern %q: %w", pattern, err)
+		}
+		r.allow = append(r.allow, re)
+	}
+
+	return r, nil
+}
+
+// Redact returns s with every detected secret replaced by a marker
+func (r *Redactor) Redact(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, rule := range r.rules {
+		s = r.apply(s, rule)
+	}
+	if r.highEntropy {
+		s = r.redactRandom(s)
+	}
+	return s
+}
+
+// redactRandom replaces random-looking strings other than checksums.
+func (r *Redactor) redactRandom(s string) string {
+	var result strings.Builder
+	last := 0
+	for _, m := range candidate.FindAllStringIndex(s, -1) {
+		value := s[m[0]:m[1]]
+		if !randomLooking(value) || r.allowed(value) || isChecksum(s, m[0]) {
+			continue
+		}
+		result.WriteString(s[last:m[0]])
+		result.WriteString(marker(highEntropyName))
+		r.counts[highEntropyName]++
+		last = m[1]
+	}
+	if last == 0 {
+		return s
+	}
+	result.WriteString(s[last:])
+	return result.String()
+}
+
+// isChecksum reports whether the candidate starting at start in s is a
+// public checksum rather than a secret.
+func isChecksum(s string, start int) bool {
+	if sri.MatchString(s[start:]) {
+		return true
+	}
+	// The prefix is short, so there's no need to look further back.
+	return checksum.MatchString(s[max(0, start-32):start])
+}
+
+// apply replaces the matches of one rule.
+func (r *Redactor) apply(s string, rule Rule) string {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 && m[2] >= 0 {
+			start, end = m[2], m[3]
+		}
+		// Matches that overlap a redaction or whose value is a call, as in
+		// password = os.Getenv("PASSWORD"), are left alone.
+		if start < last || r.allowed(s[start:end]) || strings.Contains(s[m[0]:m[1]], "[REDACTED") ||
+			(end < len(s) && s[end] == '(') {
+			continue
+		}
+		if check := plausible[rule.Name]; check != nil && !check(leadingKey(s[m[0]:start]), s[start:end]) {
+			continue
+		}
+		result.WriteString(s[last:start])
+		result.WriteString(marker(rule.Name))
+		r.counts[rule.Name]++
+		last = end
+	}
+	result.WriteString(s[last:])
+	return result.String()
+}
+
+// leadingKey returns the name at the start of a match such as
+// `password = "`, i.e. "password".
+func leadingKey(match string) string {
+	end := strings.IndexFunc(match, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-')
+	})
+	if end < 0 {
+		return match
+	}
+	return match[:end]
+}
+
+func (r *Redactor) allowed(value string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func marker(name string) string {
+	return "[REDACTED:" + name + "]"
+}
+
+// randomLooking reports whether s mixes upper case, lower case and digits
+// with the character spread of a generated token. Hex strings such as
+// commit hashes and checksums are left alone, and so are identifiers and
+// paths made of words, such as TestParseRecordFileV2WithUTF8Names.
+func randomLooking(s string) bool {
+	if wordy(s) {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit && entropy(s) >= 4.2
+}
+
+// wordy reports whether most of s is made of words of three or more lower
+// case letters, each optionally capitalised. Generated tokens rarely are.
+func wordy(s string) bool {
+	covered := 0
+	for _, w := range word.FindAllString(s, -1) {
+		covered += len(w)
+	}
+	return covered*10 >= len(s)*6
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	freq := make(map[rune]int)
+	for _, c := range s {
+		freq[c]++
+	}
+	var bits float64
+	n := float64(len(s))
+	for _, count := range freq {
+		p := float64(count) / n
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// Total returns how many items were redacted so far
+func (r *Redactor) Total() int {
+	if r == nil {
+		return 0
+	}
+	total := 0
+	for _, n := range r.counts {
+		total += n
+	}
+	return total
+}
+
+// Summary describes what was redacted, e.g. "2 github-token, 1 jwt", most
+// frequent first
+func (r *Redactor) Summary() string {
+	if r == nil {
+		return ""
+	}
+	names := make([]string, 0, len(r.counts))
+	for name := range r.counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if r.counts[names[i]] != r.counts[names[j]] {
+			return r.counts[names[i]] > r.counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%d %s", r.counts[name], name)
+	}
+	return strings.Join(parts, ", ")
+}
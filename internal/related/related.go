@@ -0,0 +1,250 @@
+package related
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Files is the set of files needed to understand a Go source file, as paths
+// relative to Root.
+type Files struct {
+	Root       string   // module root, or the file's directory outside a module
+	Target     string   // the file itself
+	Package    []string // other non-test files of the same package
+	Tests      []string // _test.go counterparts of the file
+	Referenced []string // files in other module packages declaring identifiers the file uses
+}
+
+// All returns the related paths in order of relevance: the file itself,
+// its tests, the rest of its package and then the referenced files.
+func (f *Files) All() []string {
+	var all []string
+	seen := make(map[string]bool)
+	for _, group := range [][]string{{f.Target}, f.Tests, f.Package, f.Referenced} {
+		for _, p := range group {
+			if !seen[p] {
+				seen[p] = true
+				all = append(all, p)
+			}
+		}
+	}
+	return all
+}
+
+// Find collects the files related to the Go file at path.
+func Find(path string) (*Files, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %s: %w", path, err)
+	}
+	if !strings.HasSuffix(abs, ".go") {
+		return nil, fmt.Errorf("%s is not a Go file", path)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, abs, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	dir := filepath.Dir(abs)
+	root, module := findModule(dir)
+	if root == "" {
+		root = dir
+	}
+
+	rel := func(p string) string {
+		r, err := filepath.Rel(root, p)
+		if err != nil {
+			return p
+		}
+		return filepath.ToSlash(r)
+	}
+
+	files := &Files{Root: root, Target: rel(abs)}
+
+	pkgName := strings.TrimSuffix(file.Name.Name, "_test")
+	for _, sibling := range goFiles(dir) {
+		if sibling == abs {
+			continue
+		}
+		switch {
+		case isCounterpart(abs, sibling):
+			files.Tests = append(files.Tests, rel(sibling))
+		case !strings.HasSuffix(sibling, "_test.go") && packageName(fset, sibling) == pkgName:
+			files.Package = append(files.Package, rel(sibling))
+		}
+	}
+
+	if module != "" {
+		for _, p := range referencedFiles(fset, file, root, module) {
+			files.Referenced = append(files.Referenced, rel(p))
+		}
+	}
+
+	return files, nil
+}
+
+// isCounterpart reports whether other is the test file for file, or the
+// source file for a test file.
+func isCounterpart(file, other string) bool {
+	base := strings.TrimSuffix(file, ".go")
+	if strings.HasSuffix(base, "_test") {
+		return other == strings.TrimSuffix(base, "_test")+".go"
+	}
+	return other == base+"_test.go"
+}
+
+// findModule returns the directory and module path of the go.mod governing
+// dir, or empty strings if there is none.
+func findModule(dir string) (string, string) {
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) >= 2 && fields[0] == "module" {
+					return dir, strings.Trim(fields[1], "\"`")
+				}
+			}
+			return dir, ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// goFiles returns the absolute paths of the .go files in dir, sorted.
+func goFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func packageName(fset *token.FileSet, path string) string {
+	file, err := parser.ParseFile(fset, path, nil, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return file.Name.Name
+}
+
+// referencedFiles returns the files of other packages in the module that
+// declare the package-level identifiers file refers to, such as dir.Entry or
+// output.NewReader.
+func referencedFiles(fset *token.FileSet, file *ast.File, root, module string) []string {
+	// Map local import names to the directories of module packages.
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || (path != module && !strings.HasPrefix(path, module+"/")) {
+			continue
+		}
+		dir := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(path, module)))
+
+		name := ""
+		switch {
+		case spec.Name != nil:
+			name = spec.Name.Name
+		default:
+			for _, f := range goFiles(dir) {
+				if !strings.HasSuffix(f, "_test.go") {
+					name = packageName(fset, f)
+					break
+				}
+			}
+		}
+		if name != "" && name != "_" && name != "." {
+			imports[name] = dir
+		}
+	}
+
+	// Collect the selectors used on each imported package.
+	used := make(map[string]map[string]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if dir, ok := imports[x.Name]; ok {
+				if used[dir] == nil {
+					used[dir] = make(map[string]bool)
+				}
+				used[dir][sel.Sel.Name] = true
+			}
+		}
+		return true
+	})
+
+	var result []string
+	dirs := make([]string, 0, len(used))
+	for dir := range used {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		for _, f := range goFiles(dir) {
+			if strings.HasSuffix(f, "_test.go") {
+				continue
+			}
+			if declaresAny(fset, f, used[dir]) {
+				result = append(result, f)
+			}
+		}
+	}
+	return result
+}
+
+// declaresAny reports whether the file at path declares any of names at
+// package level.
+func declaresAny(fset *token.FileSet, path string, names map[string]bool) bool {
+	file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && names[d.Name.Name] {
+				return true
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if names[s.Name.Name] {
+						return true
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if names[n.Name] {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
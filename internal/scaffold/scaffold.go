@@ -0,0 +1,99 @@
+package scaffold
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action is a directory or empty file to create
+type Action struct {
+	Path   string // relative to the target, directories end in "/"
+	IsDir  bool
+	Exists bool // already present, so it is left alone
+}
+
+// Plan works out what creating paths under target involves. It fails if a
+// path would leave target or exists with the other type, before anything is
+// created.
+func Plan(target string, paths []string) ([]Action, error) {
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	seen := make(map[string]bool)
+
+	add := func(rel string, isDir bool) error {
+		key := strings.TrimSuffix(rel, "/")
+		if seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		full := filepath.Join(absTarget, filepath.FromSlash(key))
+		if r, err := filepath.Rel(absTarget, full); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is outside %s", rel, target)
+		}
+
+		a := Action{Path: rel, IsDir: isDir}
+		if info, err := os.Lstat(full); err == nil {
+			if info.IsDir() != isDir {
+				kind := "a file"
+				if info.IsDir() {
+					kind = "a directory"
+				}
+				return fmt.Errorf("%s already exists as %s", key, kind)
+			}
+			a.Exists = true
+		}
+		actions = append(actions, a)
+		return nil
+	}
+
+	for _, p := range paths {
+		isDir := strings.HasSuffix(p, "/")
+		// Parents first, so the plan reads top-down even for bare file paths.
+		parts := strings.Split(strings.TrimSuffix(p, "/"), "/")
+		for i := 1; i < len(parts); i++ {
+			if err := add(strings.Join(parts[:i], "/")+"/", true); err != nil {
+				return nil, err
+			}
+		}
+		if err := add(p, isDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return actions, nil
+}
+
+// Apply creates the planned directories and empty files under target. Files
+// that exist, including ones created since planning, are never overwritten.
+func Apply(target string, actions []Action) error {
+	for _, a := range actions {
+		if a.Exists {
+			continue
+		}
+		full := filepath.Join(target, filepath.FromSlash(strings.TrimSuffix(a.Path, "/")))
+		if a.IsDir {
+			if err := os.MkdirAll(full, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return err
+		}
+		file, err := os.OpenFile(full, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY |
+	syscall.IN_ATTRIB | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// inotify watches every directory under root with one inotify instance
+type inotify struct {
+	file   *os.File
+	fd     int
+	ignore func(string) bool
+	events chan struct{}
+
+	mu    sync.Mutex
+	paths map[int32]string // watch descriptor to directory
+}
+
+func newNativeNotifier(root string, ignore func(string) bool) (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &inotify{
+		// A non-blocking fd is registered with the runtime poller, so Close
+		// unblocks the pending Read.
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		ignore: ignore,
+		events: make(chan struct{}, 1),
+		paths:  make(map[int32]string),
+	}
+
+	if err := n.addTree(root); err != nil {
+		n.file.Close()
+		return nil, err
+	}
+
+	go n.read()
+	return n, nil
+}
+
+// addTree adds a watch for dir and every directory below it.
+func (n *inotify) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && n.ignore(path) {
+			return filepath.SkipDir
+		}
+
+		wd, err := syscall.InotifyAddWatch(n.fd, path, inotifyMask)
+		if err != nil {
+			// Typically ENOSPC when max_user_watches is exhausted; the
+			// caller falls back to polling if the root itself fails.
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+
+		n.mu.Lock()
+		n.paths[int32(wd)] = path
+		n.mu.Unlock()
+		return nil
+	})
+}
+
+func (n *inotify) read() {
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		changed := false
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= count; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameBytes := buf[offset+syscall.SizeofInotifyEvent : offset+syscall.SizeofInotifyEvent+int(event.Len)]
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			n.mu.Lock()
+			dir := n.paths[event.Wd]
+			if event.Mask&syscall.IN_IGNORED != 0 {
+				delete(n.paths, event.Wd)
+			}
+			n.mu.Unlock()
+
+			path := filepath.Join(dir, cString(nameBytes))
+			if n.ignore(path) {
+				continue
+			}
+			changed = true
+
+			if event.Mask&syscall.IN_ISDIR != 0 && event.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+				n.addTree(path)
+			}
+		}
+
+		if changed {
+			signal(n.events)
+		}
+	}
+}
+
+func (n *inotify) Events() <-chan struct{} {
+	return n.events
+}
+
+func (n *inotify) Close() error {
+	return n.file.Close()
+}
+
+// cString returns the NUL-padded name at the end of an inotify event.
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
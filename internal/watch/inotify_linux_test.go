@@ -0,0 +1,39 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddTreeSkipsIgnored(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{"src/pkg", "node_modules/a/b"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	n, err := newNativeNotifier(root, func(p string) bool { return filepath.Base(p) == "node_modules" })
+	if err != nil {
+		t.Skipf("inotify unavailable: %v", err)
+	}
+	defer n.Close()
+
+	watched := make(map[string]bool)
+	for _, p := range n.(*inotify).paths {
+		rel, _ := filepath.Rel(root, p)
+		watched[filepath.ToSlash(rel)] = true
+	}
+	for _, want := range []string{".", "src", "src/pkg"} {
+		if !watched[want] {
+			t.Errorf("%s is not watched", want)
+		}
+	}
+	for rel := range watched {
+		if strings.HasPrefix(rel, "node_modules") {
+			t.Errorf("%s is watched, but ignored", rel)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+func newNativeNotifier(root string, ignore func(string) bool) (notifier, error) {
+	return nil, errors.New("native file notifications are not supported on this platform")
+}
@@ -0,0 +1,77 @@
+package watch
+
+import (
+	"hash/fnv"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// poller detects changes by periodically hashing the names, sizes and
+// modification times of everything under root.
+type poller struct {
+	events chan struct{}
+	done   chan struct{}
+}
+
+func newPoller(root string, interval time.Duration, ignore func(string) bool) *poller {
+	p := &poller{
+		events: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := snapshot(root, ignore)
+		for {
+			select {
+			case <-p.done:
+				return
+			case <-ticker.C:
+				if current := snapshot(root, ignore); current != last {
+					last = current
+					signal(p.events)
+				}
+			}
+		}
+	}()
+
+	return p
+}
+
+func (p *poller) Events() <-chan struct{} {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	close(p.done)
+	return nil
+}
+
+func snapshot(root string, ignore func(string) bool) uint64 {
+	h := fnv.New64a()
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if path != root && ignore(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		h.Write([]byte(path))
+		h.Write([]byte(strconv.FormatInt(info.Size(), 10)))
+		h.Write([]byte(strconv.FormatInt(info.ModTime().UnixNano(), 10)))
+		h.Write([]byte(info.Mode().String()))
+		return nil
+	})
+	return h.Sum64()
+}
@@ -0,0 +1,62 @@
+package watch
+
+import (
+	"context"
+	"time"
+)
+
+// Options for watching a directory tree
+type Options struct {
+	Debounce time.Duration          // quiet period before reporting a change (default 300ms)
+	Interval time.Duration          // polling interval when inotify is unavailable (default 1s)
+	Ignore   func(path string) bool // paths whose changes are not reported
+}
+
+// notifier reports raw, undebounced filesystem changes
+type notifier interface {
+	Events() <-chan struct{}
+	Close() error
+}
+
+// Watch blocks until ctx is cancelled, calling fn once after each burst of
+// changes under root has been quiet for the debounce period. It uses inotify
+// where available and falls back to polling otherwise.
+func Watch(ctx context.Context, root string, opts Options, fn func()) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = 300 * time.Millisecond
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Ignore == nil {
+		opts.Ignore = func(string) bool { return false }
+	}
+
+	n, err := newNativeNotifier(root, opts.Ignore)
+	if err != nil {
+		n = newPoller(root, opts.Interval, opts.Ignore)
+	}
+	defer n.Close()
+
+	timer := time.NewTimer(opts.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-n.Events():
+			timer.Reset(opts.Debounce)
+		case <-timer.C:
+			fn()
+		}
+	}
+}
+
+// signal performs a non-blocking send, coalescing bursts of events.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
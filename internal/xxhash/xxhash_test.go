@@ -0,0 +1,74 @@
+package xxhash
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Known answers from the reference implementation, seed 0.
+var golden = []struct {
+	in   string
+	want uint64
+}{
+	{"", 0xef46db3751d8e999},
+	{"a", 0xd24ec4f1a98c6e5b},
+	{"abc", 0x44bc2cf5ad770999},
+	{"Nobody inspects the spammish repetition", 0xfbcea83c8a378bf1},
+	{"The quick brown fox jumps over the lazy dog", 0x0b242d361fda71bc},
+}
+
+func TestSum64(t *testing.T) {
+	for _, tt := range golden {
+		if got := Sum64([]byte(tt.in)); got != tt.want {
+			t.Errorf("Sum64(%q) = %016x, want %016x", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestStreaming writes the inputs in chunks that straddle the 32-byte block
+// boundary, which must give the same digest as a single write.
+func TestStreaming(t *testing.T) {
+	for _, tt := range golden {
+		for _, chunk := range []int{1, 3, 7, 31, 32, 33} {
+			d := New()
+			for in := tt.in; in != ""; {
+				n := min(chunk, len(in))
+				d.Write([]byte(in[:n]))
+				in = in[n:]
+			}
+			if got := d.Sum64(); got != tt.want {
+				t.Errorf("%q in chunks of %d = %016x, want %016x", tt.in, chunk, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestLongInput(t *testing.T) {
+	in := []byte(strings.Repeat("0123456789abcdef", 1000))
+
+	d := New()
+	d.Write(in[:100])
+	d.Write(in[100:5000])
+	d.Write(in[5000:])
+	if got, want := d.Sum64(), Sum64(in); got != want {
+		t.Errorf("streamed = %016x, single write = %016x", got, want)
+	}
+
+	d.Reset()
+	d.Write([]byte("abc"))
+	if got := d.Sum64(); got != 0x44bc2cf5ad770999 {
+		t.Errorf("after Reset, Sum64(abc) = %016x", got)
+	}
+}
+
+func TestSum(t *testing.T) {
+	d := New()
+	d.Write([]byte("abc"))
+	if got := hex.EncodeToString(d.Sum([]byte{0xff})); got != "ff44bc2cf5ad770999" {
+		t.Errorf("Sum = %s, want the prefix then the big-endian digest", got)
+	}
+	if d.Size() != Size || d.BlockSize() != BlockSize {
+		t.Errorf("Size, BlockSize = %d, %d", d.Size(), d.BlockSize())
+	}
+}
@@ -0,0 +1,238 @@
+package contextkit
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates the given files, with their content, under root.
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func paths(tree *Tree) []string {
+	var result []string
+	tree.Root.Walk(func(n *Node) bool {
+		if n.Path != "" {
+			result = append(result, n.Path)
+		}
+		return true
+	})
+	return result
+}
+
+func TestBuildTree(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                    "module example\n",
+		"main.go":                   "package main\n",
+		".env":                      "TOKEN=x\n",
+		".hidden/notes":             "",
+		"node_modules/pkg/index.js": "",
+		"a/b/c/deep.go":             "package c\n",
+	})
+
+	tests := []struct {
+		name string
+		opts TreeOptions
+		want []string
+	}{
+		{
+			name: "defaults",
+			opts: TreeOptions{},
+			want: []string{"a", "a/b", "a/b/c", "a/b/c/deep.go", "node_modules", "node_modules/pkg", "node_modules/pkg/index.js", "go.mod", "main.go"},
+		},
+		{
+			name: "exclude and depth",
+			opts: TreeOptions{MaxDepth: 2, Exclude: []string{"node_modules"}},
+			want: []string{"a", "a/b", "go.mod", "main.go"},
+		},
+		{
+			name: "hidden",
+			opts: TreeOptions{MaxDepth: 1, IncludeHidden: true},
+			want: []string{".hidden", "a", "node_modules", ".env", "go.mod", "main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := BuildTree(root, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := paths(tree); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("paths = %v, want %v", got, tt.want)
+			}
+			if tree.Incomplete != "" {
+				t.Errorf("Incomplete = %q, want empty", tree.Incomplete)
+			}
+		})
+	}
+}
+
+func TestBuildTreeKeyFilesAndSecrets(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example\n",
+		".env":   "TOKEN=x\n",
+	})
+
+	tree, err := BuildTree(root, TreeOptions{IncludeHidden: true, KeyFiles: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.KeyFiles) != 1 || tree.KeyFiles[0].Path != "go.mod" || tree.KeyFiles[0].Content != "module example\n" {
+		t.Errorf("KeyFiles = %+v, want go.mod", tree.KeyFiles)
+	}
+	if len(tree.Secrets) != 1 || tree.Secrets[0] != ".env" {
+		t.Errorf("Secrets = %v, want [.env]", tree.Secrets)
+	}
+	for _, n := range tree.Root.Children {
+		if n.Name == ".env" && !n.Secret {
+			t.Error(".env is not marked secret")
+		}
+	}
+}
+
+func TestBuildTreeMaxFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a/1": "", "a/2": "", "b/3": "", "b/4": "", "c/5": "",
+	})
+
+	tree, err := BuildTree(root, TreeOptions{MaxFiles: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Incomplete == "" {
+		t.Error("Incomplete is empty, want a reason")
+	}
+	if got := paths(tree); len(got) != 3 {
+		t.Errorf("read %d entries (%v), want 3", len(got), got)
+	}
+}
+
+func TestFormatTree(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"src/main.go": "", "README.md": ""})
+
+	tree, err := BuildTree(root, TreeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := FormatTree(tree, TreeText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "└── main.go") || !strings.Contains(text, "README.md") {
+		t.Errorf("text tree is missing entries:\n%s", text)
+	}
+
+	markdown, err := FormatTree(tree, TreeMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(markdown, "```") {
+		t.Errorf("markdown tree has no code block:\n%s", markdown)
+	}
+
+	data, err := FormatTree(tree, TreeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Children []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+		} `json:"children"`
+	}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(decoded.Children) != 2 || decoded.Children[0].Name != "src" || decoded.Children[0].Type != "directory" {
+		t.Errorf("JSON children = %+v", decoded.Children)
+	}
+
+	if _, err := FormatTree(tree, "yaml"); err == nil {
+		t.Error("unknown format: want an error")
+	}
+}
+
+func TestFormatTreeIncomplete(t *testing.T) {
+	tree := &Tree{Root: Node{Name: "project", IsDir: true}, Incomplete: "timed out"}
+	text, err := FormatTree(tree, TreeText)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "timed out") {
+		t.Errorf("incomplete tree doesn't say so:\n%s", text)
+	}
+}
+
+func TestReadLog(t *testing.T) {
+	logDir := t.TempDir()
+	writeFiles(t, logDir, map[string]string{
+		"20240501_100000.000000000_make.rec": `{"version":1,"id":1,"command":"make","start_time":"2024-05-01T10:00:00Z","end_time":"2024-05-01T10:00:01Z","duration_ns":1000000000,"exit_code":0}
+ok
+`,
+		"20240501_100100.000000000_gotest.rec": `{"version":1,"id":2,"command":"go test ./...","start_time":"2024-05-01T10:01:00Z","end_time":"2024-05-01T10:01:03Z","duration_ns":3000000000,"exit_code":1,"working_dir":"/src"}
+--- FAIL: TestX
+`,
+		"20240501_100200.000000000_ls.rec": `{"version":1,"id":3,"command":"ls","start_time":"2024-05-01T10:02:00Z","end_time":"2024-05-01T10:02:00Z","duration_ns":0,"exit_code":0}
+a b
+`,
+	})
+	opts := LogOptions{LogDir: logDir, TypescriptPath: filepath.Join(logDir, "missing")}
+
+	entries, err := ReadLog(2, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("read %d entries, want 2", len(entries))
+	}
+	got := entries[0]
+	if got.ID != 2 || got.Command != "go test ./..." || got.ExitCode != 1 || got.WorkingDir != "/src" ||
+		got.Duration.Seconds() != 3 || got.Output != "--- FAIL: TestX" {
+		t.Errorf("entries[0] = %+v", got)
+	}
+	if entries[1].Command != "ls" {
+		t.Errorf("entries[1].Command = %q, want ls (oldest first)", entries[1].Command)
+	}
+
+	byID, err := ReadLogIDs([]int{3, 1}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byID) != 2 || byID[0].Command != "ls" || byID[1].Command != "make" {
+		t.Errorf("ReadLogIDs(3, 1) = %+v", byID)
+	}
+	if _, err := ReadLogIDs([]int{9}, opts); err == nil {
+		t.Error("ReadLogIDs(9): want an error")
+	}
+
+	raw := FormatLog(entries, LogRaw)
+	if want := "$ go test ./...\n--- FAIL: TestX\n$ ls\na b\n"; raw != want {
+		t.Errorf("FormatLog raw = %q, want %q", raw, want)
+	}
+}
+
+func TestReadLogMissing(t *testing.T) {
+	dir := t.TempDir()
+	_, err := ReadLog(1, LogOptions{LogDir: filepath.Join(dir, "logs"), TypescriptPath: filepath.Join(dir, "typescript")})
+	if err == nil {
+		t.Error("want an error without a log directory")
+	}
+}
@@ -0,0 +1,68 @@
+// Package contextkit is the embeddable Go API behind the context CLI.
+//
+// It exposes the directory tree generator used by `context dir` and the
+// command log reader used by `context last` as typed results, so editors,
+// bots and other tools can reuse them without shelling out to the binary and
+// parsing its text output.
+//
+// # Directory trees
+//
+// BuildTree walks a directory and returns a Tree of Nodes. FormatTree renders
+// it in the same formats as `context dir`:
+//
+//	tree, err := contextkit.BuildTree("path/to/project", contextkit.TreeOptions{
+//		MaxDepth: 3,
+//		Exclude:  []string{"node_modules", ".git"},
+//		KeyFiles: true,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	for _, secret := range tree.Secrets {
+//		log.Printf("omitted likely secret file %s", secret)
+//	}
+//	text, err := contextkit.FormatTree(tree, contextkit.TreeMarkdown)
+//
+// Trees can also be inspected or filtered before formatting:
+//
+//	tree.Root.Walk(func(n *contextkit.Node) bool {
+//		if !n.IsDir {
+//			fmt.Println(n.Path)
+//		}
+//		return true
+//	})
+//
+// # Command logs
+//
+// ReadLog returns the most recent commands captured by the shell integration,
+// oldest first, and FormatLog renders them like `context last`:
+//
+//	entries, err := contextkit.ReadLog(5, contextkit.LogOptions{})
+//	if err != nil {
+//		return err
+//	}
+//	for _, e := range entries {
+//		if e.ExitCode != 0 {
+//			fmt.Printf("%s failed with %d\n", e.Command, e.ExitCode)
+//		}
+//	}
+//	report := contextkit.FormatLog(entries, contextkit.LogMarkdown)
+//
+// Each entry's ID is the number shown by `context last -f markdown` and
+// accepted by `context show`; ReadLogIDs looks commands up by it:
+//
+//	entries, err := contextkit.ReadLogIDs([]int{42, 38}, contextkit.LogOptions{})
+//
+// # Compatibility
+//
+// This package follows semantic versioning together with the module. Within
+// a major version, exported identifiers are not removed or renamed, function
+// signatures do not change, and the meaning of existing fields is preserved.
+// New functions, option fields, struct fields and format constants may be
+// added, so construct option structs with field names rather than
+// positionally. The exact text produced by the formatters is intended for
+// humans and AI assistants and may be refined between minor versions; use the
+// typed results or TreeJSON when you need to parse output.
+//
+// Everything under internal/ remains free to change and must not be imported.
+package contextkit
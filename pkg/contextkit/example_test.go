@@ -0,0 +1,123 @@
+package contextkit_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jupiterozeye/context/pkg/contextkit"
+)
+
+// makeProject creates a small project to walk and returns its path.
+func makeProject() string {
+	root, err := os.MkdirTemp("", "example")
+	if err != nil {
+		log.Fatal(err)
+	}
+	project := filepath.Join(root, "project")
+	for _, name := range []string{"cmd/app/main.go", "go.mod", "README.md"} {
+		path := filepath.Join(project, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("module example\n"), 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	return project
+}
+
+func ExampleBuildTree() {
+	project := makeProject()
+	defer os.RemoveAll(filepath.Dir(project))
+
+	tree, err := contextkit.BuildTree(project, contextkit.TreeOptions{MaxDepth: 3})
+	if err != nil {
+		log.Fatal(err)
+	}
+	tree.Root.Walk(func(n *contextkit.Node) bool {
+		if !n.IsDir {
+			fmt.Println(n.Path)
+		}
+		return true
+	})
+	// Output:
+	// cmd/app/main.go
+	// README.md
+	// go.mod
+}
+
+func ExampleFormatTree() {
+	project := makeProject()
+	defer os.RemoveAll(filepath.Dir(project))
+
+	tree, err := contextkit.BuildTree(project, contextkit.TreeOptions{})
+	if err != nil {
+		log.Fatal(err)
+	}
+	text, err := contextkit.FormatTree(tree, contextkit.TreeText)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(text)
+	// Output:
+	// project/
+	// ├── cmd/
+	// │   └── app/
+	// │       └── main.go
+	// ├── README.md
+	// └── go.mod
+}
+
+func ExampleReadLog() {
+	logDir, err := os.MkdirTemp("", "logs")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(logDir)
+
+	// A record as written by `context record`
+	record := `{"version":1,"id":7,"command":"go test ./...","start_time":"2024-05-01T10:00:00Z","end_time":"2024-05-01T10:00:02Z","duration_ns":2000000000,"exit_code":1,"working_dir":"/src/app"}
+--- FAIL: TestParse
+FAIL
+`
+	if err := os.WriteFile(filepath.Join(logDir, "20240501_100000.000000000_gotest.rec"), []byte(record), 0o600); err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := contextkit.ReadLog(5, contextkit.LogOptions{
+		LogDir:         logDir,
+		TypescriptPath: filepath.Join(logDir, "no-typescript"),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range entries {
+		fmt.Printf("#%d %s exited %d after %s\n", e.ID, e.Command, e.ExitCode, e.Duration)
+	}
+	fmt.Print(contextkit.FormatLog(entries, contextkit.LogRaw))
+	// Output:
+	// #7 go test ./... exited 1 after 2s
+	// $ go test ./...
+	// --- FAIL: TestParse
+	// FAIL
+}
+
+func ExampleFormatLog() {
+	entries := []contextkit.LogEntry{{
+		ID:        3,
+		Command:   "echo hello",
+		StartTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Output:    "hello",
+	}}
+	fmt.Print(contextkit.FormatLog(entries, contextkit.LogMarkdown))
+	// Output:
+	// ### Command 1 (#3)
+	//
+	// ```bash
+	// $ echo hello
+	// hello
+	// ```
+}
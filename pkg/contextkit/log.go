@@ -0,0 +1,109 @@
+package contextkit
+
+import (
+	"time"
+
+	"github.com/jupiterozeye/context/internal/output"
+)
+
+// LogFormat selects how entries are rendered by FormatLog
+type LogFormat string
+
+// Formats supported by FormatLog
+const (
+	LogRaw      LogFormat = "raw"
+	LogMarkdown LogFormat = "markdown"
+	LogDetailed LogFormat = "detailed"
+)
+
+// LogOptions configures where ReadLog looks for captured commands. Empty
+// fields use the locations written by the shell integration.
+type LogOptions struct {
+	// LogDir defaults to ~/.context/logs.
+	LogDir string
+	// TypescriptPath defaults to ~/.context/typescript.
+	TypescriptPath string
+}
+
+// LogEntry is a single captured command with its output
+type LogEntry struct {
+	// ID is the number `context show` and ReadLogIDs accept, or 0 if the
+	// command has none.
+	ID         int
+	Command    string
+	StartTime  time.Time
+	EndTime    time.Time
+	Duration   time.Duration
+	ExitCode   int
+	WorkingDir string
+	Output     string
+}
+
+// ReadLog returns the last n captured commands, oldest first
+func ReadLog(n int, opts LogOptions) ([]LogEntry, error) {
+	reader := output.NewReader(output.Options{
+		LogDir:         opts.LogDir,
+		TypescriptPath: opts.TypescriptPath,
+	})
+
+	read, err := reader.Read(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromOutput(read), nil
+}
+
+// ReadLogIDs returns the captured commands with the given IDs, in that
+// order. It fails if any of them doesn't exist.
+func ReadLogIDs(ids []int, opts LogOptions) ([]LogEntry, error) {
+	reader := output.NewReader(output.Options{
+		LogDir:         opts.LogDir,
+		TypescriptPath: opts.TypescriptPath,
+	})
+
+	found, err := reader.FindIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	return fromOutput(found), nil
+}
+
+// FormatLog renders entries as text. Unknown formats fall back to
+// LogRaw, matching `context last`.
+func FormatLog(entries []LogEntry, format LogFormat) string {
+	reader := output.NewReader(output.Options{Format: string(format)})
+
+	converted := make([]output.LogEntry, 0, len(entries))
+	for _, e := range entries {
+		converted = append(converted, output.LogEntry{
+			ID:         e.ID,
+			Command:    e.Command,
+			StartTime:  e.StartTime,
+			EndTime:    e.EndTime,
+			Duration:   e.Duration,
+			ExitCode:   e.ExitCode,
+			WorkingDir: e.WorkingDir,
+			Output:     e.Output,
+		})
+	}
+
+	return reader.FormatEntries(converted)
+}
+
+func fromOutput(read []output.LogEntry) []LogEntry {
+	entries := make([]LogEntry, 0, len(read))
+	for _, e := range read {
+		entries = append(entries, LogEntry{
+			ID:         e.ID,
+			Command:    e.Command,
+			StartTime:  e.StartTime,
+			EndTime:    e.EndTime,
+			Duration:   e.Duration,
+			ExitCode:   e.ExitCode,
+			WorkingDir: e.WorkingDir,
+			Output:     e.Output,
+		})
+	}
+	return entries
+}
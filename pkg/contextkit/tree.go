@@ -0,0 +1,186 @@
+package contextkit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/jupiterozeye/context/internal/dir"
+)
+
+// TreeFormat selects how a Tree is rendered by FormatTree
+type TreeFormat string
+
+// Formats supported by FormatTree
+const (
+	TreeText     TreeFormat = "tree"
+	TreeJSON     TreeFormat = "json"
+	TreeMarkdown TreeFormat = "markdown"
+)
+
+// TreeOptions configures BuildTree
+type TreeOptions struct {
+	// MaxDepth limits how deep the walk descends; 0 means unlimited.
+	MaxDepth int
+	// Exclude lists file names or glob patterns to skip at any depth.
+	Exclude []string
+	// IncludeHidden includes dot files and directories.
+	IncludeHidden bool
+	// KeyFiles reads well-known manifest, build and CI files in the root.
+	KeyFiles bool
+	// AllowSecrets allows reading the contents of likely secret files.
+	AllowSecrets bool
+	// MaxFiles stops the walk after this many files and directories; 0
+	// means unlimited.
+	MaxFiles int
+}
+
+// Tree is a walked directory
+type Tree struct {
+	Root Node
+	// KeyFiles holds the manifest and build files read when
+	// TreeOptions.KeyFiles is set.
+	KeyFiles []KeyFile
+	// Secrets lists the root-relative paths of likely secret files.
+	Secrets []string
+	// SecretsAllowed records TreeOptions.AllowSecrets for the formatters.
+	SecretsAllowed bool
+	// Incomplete says why the walk stopped early, e.g. "timed out", or is
+	// empty if the whole directory was read.
+	Incomplete string
+}
+
+// Node is a file or directory in a Tree
+type Node struct {
+	Name string
+	// Path is relative to the tree root and uses forward slashes; it is
+	// empty for the root node.
+	Path     string
+	IsDir    bool
+	Secret   bool
+	Children []Node
+}
+
+// KeyFile is the (possibly truncated) content of a manifest or build file
+type KeyFile struct {
+	Path      string
+	Content   string
+	Truncated bool
+}
+
+// Walk calls fn for n and its descendants in depth-first order. If fn returns
+// false the children of that node are skipped.
+func (n *Node) Walk(fn func(*Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for i := range n.Children {
+		n.Children[i].Walk(fn)
+	}
+}
+
+// BuildTree walks the directory at root
+func BuildTree(root string, opts TreeOptions) (*Tree, error) {
+	return BuildTreeContext(context.Background(), root, opts)
+}
+
+// BuildTreeContext is like BuildTree but stops when ctx is done, returning the
+// partial tree with Incomplete set.
+func BuildTreeContext(ctx context.Context, root string, opts TreeOptions) (*Tree, error) {
+	generator := dir.NewGenerator(dir.Options{
+		MaxDepth:      opts.MaxDepth,
+		Exclude:       strings.Join(opts.Exclude, ","),
+		IncludeHidden: opts.IncludeHidden,
+		KeyFiles:      opts.KeyFiles,
+		AllowSecrets:  opts.AllowSecrets,
+		MaxFiles:      opts.MaxFiles,
+	})
+
+	walked, err := generator.WalkContext(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &Tree{
+		Root: Node{
+			Name:     walked.Name,
+			IsDir:    true,
+			Children: toNodes(walked.Entries, ""),
+		},
+		Secrets:        walked.Secrets,
+		SecretsAllowed: opts.AllowSecrets,
+		Incomplete:     walked.Incomplete,
+	}
+	for _, kf := range walked.KeyFiles {
+		tree.KeyFiles = append(tree.KeyFiles, KeyFile{
+			Path:      kf.Path,
+			Content:   kf.Content,
+			Truncated: kf.Truncated,
+		})
+	}
+
+	return tree, nil
+}
+
+// FormatTree renders a tree as text in the given format
+func FormatTree(tree *Tree, format TreeFormat) (string, error) {
+	switch format {
+	case TreeText, TreeJSON, TreeMarkdown:
+	default:
+		return "", fmt.Errorf("unknown format %q", format)
+	}
+
+	generator := dir.NewGenerator(dir.Options{
+		Format:       string(format),
+		AllowSecrets: tree.SecretsAllowed,
+	})
+
+	internal := &dir.Tree{
+		Name:       tree.Root.Name,
+		Entries:    fromNodes(tree.Root.Children),
+		Secrets:    tree.Secrets,
+		Incomplete: tree.Incomplete,
+	}
+	for _, kf := range tree.KeyFiles {
+		internal.KeyFiles = append(internal.KeyFiles, dir.KeyFile{
+			Path:      kf.Path,
+			Content:   kf.Content,
+			Truncated: kf.Truncated,
+		})
+	}
+
+	return generator.Format(internal)
+}
+
+func toNodes(entries []dir.Entry, parent string) []Node {
+	nodes := make([]Node, 0, len(entries))
+	for _, e := range entries {
+		path := e.Name
+		if parent != "" {
+			path = parent + "/" + e.Name
+		}
+		nodes = append(nodes, Node{
+			Name:     e.Name,
+			Path:     path,
+			IsDir:    e.IsDir,
+			Secret:   e.Secret,
+			Children: toNodes(e.Children, path),
+		})
+	}
+	return nodes
+}
+
+func fromNodes(nodes []Node) []dir.Entry {
+	entries := make([]dir.Entry, 0, len(nodes))
+	for _, n := range nodes {
+		entries = append(entries, dir.Entry{
+			Name:     n.Name,
+			Path:     filepath.FromSlash(n.Path),
+			IsDir:    n.IsDir,
+			Secret:   n.Secret,
+			Children: fromNodes(n.Children),
+		})
+	}
+	return entries
+}
@@ -7,24 +7,20 @@ CONTEXT_LOG_ENABLED=1
 CONTEXT_MAX_LOG_AGE_DAYS=30
 CONTEXT_MAX_LOG_SIZE_MB=100
 
-# Create log directory if it doesn't exist
-mkdir -p "$CONTEXT_LOG_DIR"
-
-# Function to sanitize command for filename
-_context_sanitize_cmd() {
-    echo "$1" | tr -cd '[:alnum:]._-' | cut -c1-50
-}
+# Create log directory if it doesn't exist; captured output is only readable
+# by you
+mkdir -p "$CONTEXT_LOG_DIR" && chmod 700 "$CONTEXT_LOG_DIR"
 
 # Function to clean old logs
 _context_clean_logs() {
-    find "$CONTEXT_LOG_DIR" -type f -name "*.log" -mtime +$CONTEXT_MAX_LOG_AGE_DAYS -delete 2>/dev/null
+    find "$CONTEXT_LOG_DIR" -type f \( -name "*.rec" -o -name "*.log" \) -mtime +$CONTEXT_MAX_LOG_AGE_DAYS -delete 2>/dev/null
     
     # Also check total size and delete oldest if over limit
     local total_size=$(du -sm "$CONTEXT_LOG_DIR" 2>/dev/null | cut -f1)
     if [[ $total_size -gt $CONTEXT_MAX_LOG_SIZE_MB ]]; then
         # Delete oldest files until under limit
         while [[ $total_size -gt $CONTEXT_MAX_LOG_SIZE_MB ]]; do
-            local oldest=$(find "$CONTEXT_LOG_DIR" -type f -name "*.log" -printf '%T+ %p\n' 2>/dev/null | sort | head -1 | cut -d' ' -f2)
+            local oldest=$(find "$CONTEXT_LOG_DIR" -type f \( -name "*.rec" -o -name "*.log" \) -printf '%T+ %p\n' 2>/dev/null | sort | head -1 | cut -d' ' -f2)
             if [[ -n "$oldest" ]]; then
                 rm -f "$oldest"
                 total_size=$(du -sm "$CONTEXT_LOG_DIR" 2>/dev/null | cut -f1)
@@ -46,12 +42,9 @@ _context_debug_trap() {
     fi
     
     CONTEXT_SKIP_LOGGING=0
-    local timestamp=$(date +%Y%m%d_%H%M%S)
-    local sanitized_cmd=$(_context_sanitize_cmd "$cmd")
-    
-    CONTEXT_CURRENT_LOG="${CONTEXT_LOG_DIR}/${timestamp}_${sanitized_cmd}.log"
     CONTEXT_CURRENT_CMD="$cmd"
-    CONTEXT_CMD_START_TIME=$(date +%s)
+    # Microsecond resolution on bash 5+, whole seconds otherwise
+    CONTEXT_CMD_START_TIME=${EPOCHREALTIME:-$(date +%s)}
     CONTEXT_OUTPUT_FILE=$(mktemp)
     
     # Redirect output to temp file and terminal
@@ -70,33 +63,25 @@ _context_prompt_command() {
     fi
     
     # Skip if we shouldn't log this command
-    if [[ ${CONTEXT_SKIP_LOGGING:-0} -eq 1 ]] || [[ -z "$CONTEXT_CURRENT_LOG" ]]; then
+    if [[ ${CONTEXT_SKIP_LOGGING:-0} -eq 1 ]] || [[ -z "$CONTEXT_CURRENT_CMD" ]]; then
         CONTEXT_SKIP_LOGGING=0
-        CONTEXT_CURRENT_LOG=""
         CONTEXT_CURRENT_CMD=""
+        [[ -n "$CONTEXT_OUTPUT_FILE" ]] && rm -f "$CONTEXT_OUTPUT_FILE"
         CONTEXT_OUTPUT_FILE=""
         return 0
     fi
     
-    local end_time=$(date +%s)
-    local duration=$((end_time - CONTEXT_CMD_START_TIME))
-    
-    # Write log entry
-    cat > "$CONTEXT_CURRENT_LOG" <<EOF
-=== COMMAND: ${CONTEXT_CURRENT_CMD}
-=== START_TIME: $(date -d @$CONTEXT_CMD_START_TIME '+%Y-%m-%d %H:%M:%S' 2>/dev/null || date -r $CONTEXT_CMD_START_TIME '+%Y-%m-%d %H:%M:%S' 2>/dev/null || echo "$(date)")
-=== END_TIME: $(date '+%Y-%m-%d %H:%M:%S')
-=== DURATION: ${duration}s
-=== EXIT_CODE: ${exit_code}
-=== WORKING_DIR: $(pwd)
-EOF
-    
-    # Add output if available
-    if [[ -f "$CONTEXT_OUTPUT_FILE" && -s "$CONTEXT_OUTPUT_FILE" ]]; then
-        echo "=== OUTPUT:" >> "$CONTEXT_CURRENT_LOG"
-        cat "$CONTEXT_OUTPUT_FILE" >> "$CONTEXT_CURRENT_LOG"
-        rm -f "$CONTEXT_OUTPUT_FILE"
-    fi
+    # Write a log record; the command line is passed as a single argument so
+    # multi-line commands are stored intact
+    command context record \
+        --command "$CONTEXT_CURRENT_CMD" \
+        --start "$CONTEXT_CMD_START_TIME" \
+        --exit-code "$exit_code" \
+        --cwd "$PWD" \
+        --output-file "$CONTEXT_OUTPUT_FILE" \
+        --log-dir "$CONTEXT_LOG_DIR" \
+        --shell bash 2>/dev/null
+    rm -f "$CONTEXT_OUTPUT_FILE"
     
     # Clean old logs periodically (1% chance)
     if [[ $((RANDOM % 100)) -eq 0 ]]; then
@@ -105,7 +90,6 @@ EOF
     
     # Reset
     CONTEXT_SKIP_LOGGING=0
-    CONTEXT_CURRENT_LOG=""
     CONTEXT_CURRENT_CMD=""
     CONTEXT_OUTPUT_FILE=""
 }
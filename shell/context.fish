@@ -7,23 +7,18 @@ set -g CONTEXT_LOG_ENABLED 1
 set -g CONTEXT_MAX_LOG_AGE_DAYS 30
 set -g CONTEXT_MAX_LOG_SIZE_MB 100
 
-# Create log directory
-mkdir -p $CONTEXT_LOG_DIR
-
-# Function to sanitize command for filename
-function _context_sanitize_cmd
-    echo $argv | tr -cd '[:alnum:]._-' | cut -c1-50
-end
+# Create log directory; captured output is only readable by you
+mkdir -p $CONTEXT_LOG_DIR; and chmod 700 $CONTEXT_LOG_DIR
 
 # Function to clean old logs
 function _context_clean_logs
-    find $CONTEXT_LOG_DIR -type f -name "*.log" -mtime +$CONTEXT_MAX_LOG_AGE_DAYS -delete 2>/dev/null
+    find $CONTEXT_LOG_DIR -type f \( -name "*.rec" -o -name "*.log" \) -mtime +$CONTEXT_MAX_LOG_AGE_DAYS -delete 2>/dev/null
     
     # Check total size
     set total_size (du -sm $CONTEXT_LOG_DIR 2>/dev/null | cut -f1)
     if test $total_size -gt $CONTEXT_MAX_LOG_SIZE_MB
         while test $total_size -gt $CONTEXT_MAX_LOG_SIZE_MB
-            set oldest (find $CONTEXT_LOG_DIR -type f -name "*.log" -printf '%T+ %p\n' 2>/dev/null | sort | head -1 | cut -d' ' -f2)
+            set oldest (find $CONTEXT_LOG_DIR -type f \( -name "*.rec" -o -name "*.log" \) -printf '%T+ %p\n' 2>/dev/null | sort | head -1 | cut -d' ' -f2)
             if test -n "$oldest"
                 rm -f $oldest
                 set total_size (du -sm $CONTEXT_LOG_DIR 2>/dev/null | cut -f1)
@@ -45,12 +40,6 @@ function _context_preexec --on-event fish_preexec
     end
     
     set -g CONTEXT_SKIP_LOGGING 0
-    set timestamp (date +%Y%m%d_%H%M%S)
-    set sanitized_cmd (_context_sanitize_cmd $CONTEXT_CURRENT_CMD)
-    
-    set -g CONTEXT_CURRENT_LOG "$CONTEXT_LOG_DIR/$timestamp-$sanitized_cmd.log"
-    set -g CONTEXT_CMD_START_TIME (date +%s)
-    set -g CONTEXT_OUTPUT_FILE (mktemp)
 end
 
 # Function called after command execution  
@@ -58,23 +47,21 @@ function _context_postexec --on-event fish_postexec
     set exit_code $status
     
     # Skip if we shouldn't log
-    if test "$CONTEXT_SKIP_LOGGING" = "1"; or test -z "$CONTEXT_CURRENT_LOG"
+    set duration $CMD_DURATION
+    if test "$CONTEXT_SKIP_LOGGING" = "1"; or test -z "$CONTEXT_CURRENT_CMD"
         set -g CONTEXT_SKIP_LOGGING 0
-        set -g CONTEXT_CURRENT_LOG ""
         set -g CONTEXT_CURRENT_CMD ""
         return
     end
     
-    set end_time (date +%s)
-    set duration (math $end_time - $CONTEXT_CMD_START_TIME)
-    
-    # Write log entry
-    echo "=== COMMAND: $CONTEXT_CURRENT_CMD" > $CONTEXT_CURRENT_LOG
-    echo "=== START_TIME: "(date -d @$CONTEXT_CMD_START_TIME '+%Y-%m-%d %H:%M:%S' 2>/dev/null; or date -r $CONTEXT_CMD_START_TIME '+%Y-%m-%d %H:%M:%S' 2>/dev/null; or date) >> $CONTEXT_CURRENT_LOG
-    echo "=== END_TIME: "(date '+%Y-%m-%d %H:%M:%S') >> $CONTEXT_CURRENT_LOG
-    echo "=== DURATION: {$duration}s" >> $CONTEXT_CURRENT_LOG
-    echo "=== EXIT_CODE: $exit_code" >> $CONTEXT_CURRENT_LOG
-    echo "=== WORKING_DIR: "(pwd) >> $CONTEXT_CURRENT_LOG
+    # Write a log record; fish measures the run time itself
+    command context record \
+        --command "$CONTEXT_CURRENT_CMD" \
+        --duration-ms "$duration" \
+        --exit-code "$exit_code" \
+        --cwd "$PWD" \
+        --log-dir "$CONTEXT_LOG_DIR" \
+        --shell fish 2>/dev/null
     
     # Clean old logs periodically (1% chance)
     if test (random) -lt 327
@@ -83,7 +70,6 @@ function _context_postexec --on-event fish_postexec
     
     # Reset
     set -g CONTEXT_SKIP_LOGGING 0
-    set -g CONTEXT_CURRENT_LOG ""
     set -g CONTEXT_CURRENT_CMD ""
 end
 